@@ -1,97 +1,191 @@
 package assets
 
 import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io/fs"
 	"log"
+	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 )
 
-// Assets almacena todos los sprites cargados en memoria.
+// Assets almacena todos los sprites cargados en memoria. La geometría de
+// cada sprite sale del manifest.json del skin.
 type Assets struct {
+	// Nombre del skin según su manifest
+	Skin string
+
 	// Fondo
-	Background *ebiten.Image
+	Background *Sprite
 
 	// Hardware
-	GeovaTripod *ebiten.Image
-	UITiltMeter *ebiten.Image // (El medidor de inclinación)
+	GeovaTripod *Sprite
+	UITiltMeter *Sprite // (El medidor de inclinación)
 
 	// Iconos de Backend (Inactivos)
-	IconPythonIdle    *ebiten.Image
-	IconRabbitIdle    *ebiten.Image
-	IconWebsocketIdle *ebiten.Image
+	IconPythonIdle    *Sprite
+	IconRabbitIdle    *Sprite
+	IconWebsocketIdle *Sprite
 
 	// Iconos de Backend (Animados)
-	IconPythonActiveAnim    *ebiten.Image
-	IconRabbitActiveAnim    *ebiten.Image
-	IconWebsocketActiveAnim *ebiten.Image
+	IconPythonActiveAnim    *Sprite
+	IconRabbitActiveAnim    *Sprite
+	IconWebsocketActiveAnim *Sprite
 
 	// Paquete de Datos (Animado)
-	DataPacketAnim *ebiten.Image
+	DataPacketAnim *Sprite
 
 	// Frontend
-	IconMonitor    *ebiten.Image
-	UIGaugeBG      *ebiten.Image
-	UIGaugeNeedle  *ebiten.Image
-	UIProgressBG   *ebiten.Image
-	UIProgressFill *ebiten.Image
+	IconMonitor    *Sprite
+	UIGaugeBG      *Sprite
+	UIGaugeNeedle  *Sprite
+	UIProgressBG   *Sprite
+	UIProgressFill *Sprite
+
+	// Panel del frontend
+	FrontendMonitor *Sprite
+	CameraView      *Sprite // Vista simulada de la IMX477 (animada)
+	LaserDot        *Sprite
 
 	// Botones
-	ButtonCreateUp   *ebiten.Image
-	ButtonCreateDown *ebiten.Image
+	ButtonCreateUp   *Sprite
+	ButtonCreateDown *Sprite
+}
+
+// loader acumula los assets obligatorios que no se pudieron cargar para
+// reportarlos todos juntos.
+type loader struct {
+	fsys     fs.FS
+	manifest *Manifest
+	missing  []error
 }
 
-// loadSprite es un helper interno para cargar una imagen o fallar.
-func loadSprite(path string) *ebiten.Image {
-	img, _, err := ebitenutil.NewImageFromFile(path)
+func decodeSprite(fsys fs.FS, name string) (*ebiten.Image, error) {
+	f, err := fsys.Open(name)
 	if err != nil {
-		log.Fatalf("Error: No se pudo cargar el asset '%s': %v", path, err)
+		return nil, err
 	}
-	return img
-}
+	defer f.Close()
 
-// loadSpriteOptional carga un sprite, pero retorna nil si no existe (sin fallar)
-func loadSpriteOptional(path string) *ebiten.Image {
-	img, _, err := ebitenutil.NewImageFromFile(path)
+	img, _, err := image.Decode(f)
 	if err != nil {
-		log.Printf("Advertencia: No se pudo cargar el asset opcional '%s': %v", path, err)
+		return nil, err
+	}
+	return ebiten.NewImageFromImage(img), nil
+}
+
+// sprite carga el sprite key del manifest. Si falla y es obligatorio lo anota
+// como faltante; si es opcional solo avisa. En ambos casos retorna nil.
+func (l *loader) sprite(key string) *Sprite {
+	spec, ok := l.manifest.Sprites[key]
+	if !ok {
+		l.missing = append(l.missing, fmt.Errorf("sprite '%s' no está en el manifest", key))
 		return nil
 	}
-	return img
+
+	s, err := l.load(spec)
+	if err == nil {
+		return s
+	}
+	if spec.Optional {
+		log.Printf("Advertencia: No se pudo cargar el asset opcional '%s': %v", spec.File, err)
+	} else {
+		l.missing = append(l.missing, fmt.Errorf("asset '%s' (%s): %w", spec.File, key, err))
+	}
+	return nil
+}
+
+func (l *loader) load(spec SpriteSpec) (*Sprite, error) {
+	img, err := decodeSprite(l.fsys, spec.File)
+	if err != nil {
+		return nil, err
+	}
+	return newSprite(img, spec)
 }
 
-// LoadAssets carga todas las imágenes del juego desde la carpeta /images
-func LoadAssets() *Assets {
-	return &Assets{
+// LoadAssets carga todas las imágenes del juego desde fsys, cuya raíz es el
+// contenido de la carpeta images/ (PNG + manifest.json). Si falta algún asset
+// obligatorio retorna un error que los lista a todos.
+func LoadAssets(fsys fs.FS) (*Assets, error) {
+	manifest, err := loadManifest(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el manifest: %w", err)
+	}
+
+	l := &loader{fsys: fsys, manifest: manifest}
+	a := &Assets{
+		Skin: manifest.Name,
+
 		// Fondo (opcional)
-		Background: loadSpriteOptional("images/background.png"),
+		Background: l.sprite("background"),
 
 		// Hardware
-		GeovaTripod: loadSprite("images/geova_tripod.png"),
-		UITiltMeter: loadSprite("images/geova_tilt_anim.png"),
+		GeovaTripod: l.sprite("geova_tripod"),
+		UITiltMeter: l.sprite("tripod_tilt"),
 
 		// Iconos Inactivos
-		IconPythonIdle:    loadSprite("images/icon_api_python_idle.png"),
-		IconRabbitIdle:    loadSprite("images/icon_rabbitmq_idle.png"),
-		IconWebsocketIdle: loadSprite("images/icon_api_websocket_idle.png"),
+		IconPythonIdle:    l.sprite("icon_python_idle"),
+		IconRabbitIdle:    l.sprite("icon_rabbit_idle"),
+		IconWebsocketIdle: l.sprite("icon_websocket_idle"),
 
 		// Iconos Animados (Sprite Sheets)
-		IconPythonActiveAnim:    loadSprite("images/icon_api_python_active_anim.png"),
-		IconRabbitActiveAnim:    loadSprite("images/icon_rabbitmq_active_anim.png"),
-		IconWebsocketActiveAnim: loadSprite("images/icon_api_websocket_active_anim.png"),
+		IconPythonActiveAnim:    l.sprite("icon_python_active"),
+		IconRabbitActiveAnim:    l.sprite("icon_rabbit_active"),
+		IconWebsocketActiveAnim: l.sprite("icon_websocket_active"),
 
 		// Paquete de Datos (Sprite Sheet)
-		DataPacketAnim: loadSprite("images/data_packet_anim.png"),
+		DataPacketAnim: l.sprite("data_packet"),
 
 		// Frontend
-		IconMonitor:    loadSprite("images/monitor.png"),
-		UIGaugeBG:      loadSprite("images/ui_gauge_background.png"),
-		UIGaugeNeedle:  loadSprite("images/ui_gauge_needle.png"),
-		UIProgressBG:   loadSprite("images/ui_progressbar_background.png"),
-		UIProgressFill: loadSprite("images/ui_progressbar_fill.png"),
+		IconMonitor:    l.sprite("monitor"),
+		UIGaugeBG:      l.sprite("gauge_background"),
+		UIGaugeNeedle:  l.sprite("gauge_needle"),
+		UIProgressBG:   l.sprite("progress_background"),
+		UIProgressFill: l.sprite("progress_fill"),
+
+		// Panel del frontend
+		FrontendMonitor: l.sprite("frontend_monitor"),
+		CameraView:      l.sprite("camera_view"),
+		LaserDot:        l.sprite("laser_dot"),
 
 		// Botones
-		ButtonCreateUp:   loadSprite("images/boton_crear_up.png"),
-		ButtonCreateDown: loadSprite("images/boton_crear_down.png"),
+		ButtonCreateUp:   l.sprite("button_create_up"),
+		ButtonCreateDown: l.sprite("button_create_down"),
+	}
+	if len(l.missing) > 0 {
+		return nil, fmt.Errorf("faltan assets obligatorios: %w", errors.Join(l.missing...))
+	}
+	return a, nil
+}
+
+// overlayFS busca cada archivo primero en un directorio de skin y, si no
+// está, en los assets embebidos.
+type overlayFS struct {
+	override fs.FS
+	base     fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.override.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.base.Open(name)
+	}
+	return f, err
+}
+
+// WithOverride combina un directorio de skin con los assets base. Solo hace
+// falta poner en dir los PNG que se quieren reemplazar y, si cambia su
+// geometría, un manifest.json con esas entradas.
+func WithOverride(dir string, base fs.FS) (fs.FS, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("'%s' no es un directorio", dir)
 	}
+	return overlayFS{override: os.DirFS(dir), base: base}, nil
 }
@@ -0,0 +1,70 @@
+package assets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+const manifestFile = "manifest.json"
+
+// SpriteSpec describe un sprite del manifest: el PNG y, si es un sprite
+// sheet, la geometría de sus frames (en horizontal, de izquierda a derecha).
+type SpriteSpec struct {
+	File            string `json:"file"`
+	FrameWidth      int    `json:"frame_width,omitempty"`
+	FrameHeight     int    `json:"frame_height,omitempty"`
+	Frames          int    `json:"frames,omitempty"`
+	FrameDurationMs int    `json:"frame_duration_ms,omitempty"`
+	Optional        bool   `json:"optional,omitempty"`
+}
+
+// Manifest es el contenido de manifest.json de un skin.
+type Manifest struct {
+	Name    string                `json:"name"`
+	Sprites map[string]SpriteSpec `json:"sprites"`
+}
+
+func readManifest(fsys fs.FS) (*Manifest, error) {
+	data, err := fs.ReadFile(fsys, manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", manifestFile, err)
+	}
+	return &m, nil
+}
+
+// loadManifest lee el manifest de fsys. Con un skin (overlayFS), el manifest
+// del skin es opcional y sus entradas reemplazan a las del manifest base, así
+// un skin solo declara los sprites que cambia.
+func loadManifest(fsys fs.FS) (*Manifest, error) {
+	overlay, ok := fsys.(overlayFS)
+	if !ok {
+		return readManifest(fsys)
+	}
+
+	base, err := readManifest(overlay.base)
+	if err != nil {
+		return nil, err
+	}
+	skin, err := readManifest(overlay.override)
+	if errors.Is(err, fs.ErrNotExist) {
+		return base, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &Manifest{Name: skin.Name, Sprites: make(map[string]SpriteSpec)}
+	for k, v := range base.Sprites {
+		merged.Sprites[k] = v
+	}
+	for k, v := range skin.Sprites {
+		merged.Sprites[k] = v
+	}
+	return merged, nil
+}
@@ -0,0 +1,76 @@
+package assets
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Sprite es una imagen con la geometría de frames declarada en el manifest.
+// Un sprite estático es un sheet de un solo frame.
+type Sprite struct {
+	Image         *ebiten.Image
+	FrameWidth    int
+	FrameHeight   int
+	FrameDuration time.Duration
+
+	frames []*ebiten.Image
+}
+
+func newSprite(img *ebiten.Image, spec SpriteSpec) (*Sprite, error) {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	count := spec.Frames
+	if count <= 0 {
+		count = 1
+	}
+	fw, fh := spec.FrameWidth, spec.FrameHeight
+	if fw <= 0 {
+		fw = w / count
+	}
+	if fh <= 0 {
+		fh = h
+	}
+	if fw*count > w || fh > h {
+		return nil, fmt.Errorf("%d frames de %dx%d no caben en la imagen de %dx%d",
+			count, fw, fh, w, h)
+	}
+
+	s := &Sprite{
+		Image:         img,
+		FrameWidth:    fw,
+		FrameHeight:   fh,
+		FrameDuration: time.Duration(spec.FrameDurationMs) * time.Millisecond,
+		frames:        make([]*ebiten.Image, count),
+	}
+	for i := range s.frames {
+		rect := image.Rect(i*fw, 0, (i+1)*fw, fh)
+		s.frames[i] = img.SubImage(rect).(*ebiten.Image)
+	}
+	return s, nil
+}
+
+func (s *Sprite) FrameCount() int {
+	return len(s.frames)
+}
+
+// Frame devuelve el frame i, acotado al rango del sheet.
+func (s *Sprite) Frame(i int) *ebiten.Image {
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(s.frames) {
+		i = len(s.frames) - 1
+	}
+	return s.frames[i]
+}
+
+// FrameAt devuelve el frame de la animación en el instante t, en bucle.
+func (s *Sprite) FrameAt(t time.Duration) *ebiten.Image {
+	if s.FrameDuration <= 0 || len(s.frames) == 1 {
+		return s.frames[0]
+	}
+	return s.frames[int(t/s.FrameDuration)%len(s.frames)]
+}
@@ -0,0 +1,47 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock abstrae la fuente de tiempo con la que avanza la simulación, para que
+// la UI, el runner headless y las pruebas compartan la misma línea de tiempo.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// Real usa el reloj de pared del sistema.
+type Real struct{}
+
+func (Real) Now() time.Time { return time.Now() }
+
+func (Real) Sleep(d time.Duration) { time.Sleep(d) }
+
+// Fake es un reloj para pruebas que solo avanza con Advance o Sleep. Sleep no
+// bloquea: adelanta el reloj la duración pedida y retorna de inmediato.
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.now = f.now.Add(d)
+	f.mu.Unlock()
+}
+
+func (f *Fake) Sleep(d time.Duration) {
+	f.Advance(d)
+}
@@ -0,0 +1,276 @@
+// Command headless corre la misma simulación que la UI pero sin ventana,
+// avanzando la FSM a un TPS fijo contra el reloj real.
+//
+// Al final evalúa las expectativas del escenario y las de los flags -expect,
+// -min-success, -max-p95, -no-errors y -expect-done. El código de salida es
+// 0 si se cumplen todas, 1 si alguna falla o la corrida no terminó antes de
+// -timeout y 2 si la configuración es inválida.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"geova-simulation/clock"
+	"geova-simulation/codec"
+	"geova-simulation/expect"
+	"geova-simulation/pipeline"
+	"geova-simulation/scenario"
+	"geova-simulation/simulation"
+	"geova-simulation/state"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxTPS acota -tps: la FSM arrastra el tiempo sobrante de cada tick, así
+// que más ticks no la hacen más precisa y solo ocupan la CPU.
+const maxTPS = 1000
+
+func main() {
+	tps := flag.Int("tps", 60, "ticks por segundo de la FSM")
+	scale := flag.Float64("scale", 1.0, "escala de tiempo de la simulación")
+	tilt := flag.Float64("tilt", 0.0, "inclinación inicial de los trípodes en grados (roll)")
+	pitch := flag.Float64("pitch", 0.0, "pitch inicial de los trípodes en grados")
+	devices := flag.Int("devices", 1, "cantidad de Geova que envían datos")
+	devicesPath := flag.String("device-config", "", "archivo JSON con el proyecto y los sensores de cada Geova")
+	stagesPath := flag.String("stages", "", "archivo JSON con la capacidad y el tiempo de servicio de cada etapa")
+	timeout := flag.Duration("timeout", 30*time.Second, "tiempo máximo de ejecución")
+	stream := flag.Duration("stream", 0, "si es > 0, envía muestras del MPU en streaming durante este tiempo")
+	scenarioPath := flag.String("scenario", "", "archivo de escenario a ejecutar en lugar de -stream o una simulación")
+	schema := flag.String("schema", string(simulation.SchemaV1), "versión de los payloads (v1, v2) o archivo .json con un mapeo de campos")
+	encoding := flag.String("encoding", string(codec.JSON), "formato del cuerpo de las peticiones (json, msgpack, cbor, protobuf)")
+	compress := flag.String("compress", "none", "compresión del cuerpo de las peticiones (none, gzip, zstd)")
+	authPath := flag.String("auth", "", "archivo JSON con las credenciales de cada endpoint (sin él se leen las variables GEOVA_AUTH_*)")
+	batchSize := flag.Int("batch", 0, "en streaming, agrupa hasta N lecturas de cada sensor en un solo POST")
+	batchWindow := flag.Duration("batch-window", 0, "en streaming, envía el lote cuando pasa este tiempo desde su primera lectura")
+	batchFormat := flag.String("batch-format", string(simulation.BatchJSON), "formato de los lotes (json, ndjson)")
+	batchURL := flag.String("batch-url", "", "endpoint de los lotes; {sensor} se reemplaza por el sensor (por defecto <URL del sensor>/batch)")
+	var exprs expectFlag
+	flag.Var(&exprs, "expect", "expectativa a evaluar al final, p. ej. \"p99 <= 3s\" (se puede repetir)")
+	minSuccess := flag.String("min-success", "", "proporción mínima de paquetes en Done, p. ej. 0.95 o 95%")
+	maxP95 := flag.Duration("max-p95", 0, "si es > 0, latencia p95 máxima de los paquetes en Done")
+	noErrors := flag.String("no-errors", "", "sensores que no pueden tener paquetes en Error, separados por comas, o \"all\"")
+	expectDone := flag.Int("expect-done", 0, "si es > 0, cantidad exacta de paquetes que deben llegar a Done")
+	junitPath := flag.String("junit", "", "archivo donde escribir el reporte JUnit XML")
+	flag.Parse()
+
+	if *tps < 1 || *tps > maxTPS {
+		configError("-tps debe estar entre 1 y %d", maxTPS)
+	}
+	// Workers y FSM comparten el mismo reloj
+	clk := clock.Real{}
+	cfg := pipeline.DefaultConfig()
+	var err error
+	if cfg.Schema, err = simulation.ParseSchema(*schema); err != nil {
+		configError("%v", err)
+	}
+	if cfg.Encoding, err = codec.Parse(*encoding); err != nil {
+		configError("%v", err)
+	}
+	if cfg.Compression, err = simulation.ParseContentEncoding(*compress); err != nil {
+		configError("%v", err)
+	}
+	if cfg.Auth, err = simulation.LoadAuth(*authPath, clk); err != nil {
+		configError("%v", err)
+	}
+	for _, rule := range cfg.Auth {
+		log.Printf("🔐 Auth %s", rule)
+	}
+	if *minSuccess != "" {
+		exprs = append(exprs, "success >= "+*minSuccess)
+	}
+	if *maxP95 > 0 {
+		exprs = append(exprs, "p95 <= "+maxP95.String())
+	}
+	if *noErrors == "all" {
+		exprs = append(exprs, "errors == 0")
+	} else if *noErrors != "" {
+		for _, sensor := range strings.Split(*noErrors, ",") {
+			exprs = append(exprs, "errors."+strings.TrimSpace(sensor)+" == 0")
+		}
+	}
+	if *expectDone > 0 {
+		exprs = append(exprs, fmt.Sprintf("done == %d", *expectDone))
+	}
+	var expectations []expect.Expectation
+	for _, expr := range exprs {
+		e, err := expect.Parse(expr)
+		if err != nil {
+			configError("expectativa '%s': %v", expr, err)
+		}
+		expectations = append(expectations, e)
+	}
+
+	if cfg.Devices, err = pipeline.LoadDevices(*devices, *devicesPath); err != nil {
+		configError("%v", err)
+	}
+	if *stagesPath != "" {
+		if cfg, err = pipeline.LoadStages(*stagesPath, cfg); err != nil {
+			configError("%v", err)
+		}
+	}
+	cfg.Batch.Size, cfg.Batch.Window, cfg.Batch.URL = *batchSize, *batchWindow, *batchURL
+	if cfg.Batch.Format, err = simulation.ParseBatchFormat(*batchFormat); err == nil {
+		err = cfg.Batch.Check(cfg.Encoding)
+	}
+	if err != nil {
+		configError("%v", err)
+	}
+	if cfg.Batch.Enabled() && *stream == 0 && *scenarioPath == "" {
+		configError("-batch y -batch-window solo agrupan el streaming (-stream o un escenario)")
+	}
+	for i := range cfg.Devices {
+		cfg.Devices[i].Tilt, cfg.Devices[i].Pitch = *tilt, *pitch
+	}
+	visualState := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+	pipe := pipeline.New(visualState, clk, cfg)
+
+	suite := "headless"
+	var runner *scenario.Runner
+	if *scenarioPath != "" {
+		sc, err := scenario.Load(*scenarioPath)
+		if err != nil {
+			configError("%v", err)
+		}
+		if runner, err = scenario.NewRunner(sc, pipe); err != nil {
+			configError("%s: %v", *scenarioPath, err)
+		}
+		suite = sc.Name
+		expectations = append(sc.Expectations, expectations...)
+	}
+	rec := expect.NewRecorder()
+
+	log.Println("🚀 Iniciando simulación headless...")
+	var streamEnd <-chan time.Time
+	switch {
+	case runner != nil:
+		// Los pasos del escenario deciden cuándo arrancar
+	case *stream > 0:
+		pipe.StartStreaming()
+		streamEnd = time.After(*stream)
+	default:
+		pipe.Start()
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(*tps))
+	defer ticker.Stop()
+	deadline := time.After(*timeout)
+	start := time.Now()
+	timedOut := false
+
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			dt := pipe.Tick(*scale)
+			rec.Observe(visualState)
+			if runner != nil {
+				runner.Advance(dt)
+				if runner.Finished() {
+					break loop
+				}
+				continue
+			}
+			visualState.Mutex.Lock()
+			running := visualState.SimulacionIniciada
+			visualState.Mutex.Unlock()
+			if !running {
+				break loop
+			}
+		case <-streamEnd:
+			log.Println("⏹  Fin del streaming, esperando paquetes en vuelo")
+			pipe.StopStreaming()
+		case <-deadline:
+			log.Printf("⏱  Tiempo máximo de %s alcanzado", *timeout)
+			timedOut = true
+			break loop
+		}
+	}
+
+	elapsed := time.Since(start)
+	printSummary(visualState, rec.Stats(""), elapsed)
+
+	results := expect.Evaluate(expectations, rec)
+	if timedOut {
+		results = append(results, expect.Result{
+			Name: "terminó antes de -timeout",
+			Got:  fmt.Sprintf("tiempo máximo de %s alcanzado", *timeout),
+		})
+	}
+	if len(results) > 0 {
+		expect.Report(os.Stdout, suite, results)
+	}
+	if *junitPath != "" {
+		if err := writeJUnit(*junitPath, suite, results, elapsed); err != nil {
+			log.Printf("Error escribiendo el reporte JUnit: %v", err)
+			os.Exit(1)
+		}
+	}
+	if !expect.Passed(results) {
+		os.Exit(1)
+	}
+}
+
+// expectFlag junta los -expect repetidos.
+type expectFlag []string
+
+func (f *expectFlag) String() string { return strings.Join(*f, "; ") }
+
+func (f *expectFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// configError termina con código 2, distinto del de una corrida que no
+// cumplió sus expectativas.
+func configError(format string, args ...any) {
+	log.Printf("Error: "+format, args...)
+	os.Exit(2)
+}
+
+func writeJUnit(path, suite string, results []expect.Result, elapsed time.Duration) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := expect.WriteJUnit(f, suite, results, elapsed); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// printSummary lista los paquetes que siguen en el estado y el throughput de
+// toda la corrida, que sale de stats porque el streaming descarta los
+// paquetes terminados.
+func printSummary(visState *state.VisualState, stats expect.Stats, elapsed time.Duration) {
+	visState.Mutex.Lock()
+	defer visState.Mutex.Unlock()
+
+	ids := make([]string, 0, len(visState.Packets))
+	for id := range visState.Packets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fmt.Println("--- Resumen ---")
+	for _, id := range ids {
+		packet := visState.Packets[id]
+		fmt.Printf("  %-18s %-20s %5d B", id, packet.Status, len(packet.Body))
+		if packet.ContentEncoding != "" {
+			fmt.Printf("  %5d B %s", packet.SentBytes, packet.ContentEncoding)
+		}
+		fmt.Println()
+	}
+	for _, stage := range []*state.StageState{visState.PythonAPI, visState.RabbitMQ, visState.WebsocketAPI} {
+		fmt.Printf("  %-14s uso %3.0f%%\n", stage.Name, stage.Utilisation()*100)
+	}
+	fmt.Printf("  %d lecturas en %d paquetes en Done, %.2f lecturas/s en %s\n",
+		stats.Readings, stats.Done, float64(stats.Readings)/elapsed.Seconds(), elapsed.Round(time.Millisecond))
+	if stats.BodyBytes > 0 && stats.SentBytes != stats.BodyBytes {
+		fmt.Printf("  %d B en la red de %d B serializados (%.0f%%)\n",
+			stats.SentBytes, stats.BodyBytes, 100*float64(stats.SentBytes)/float64(stats.BodyBytes))
+	}
+}
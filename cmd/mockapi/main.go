@@ -0,0 +1,105 @@
+// Command mockapi es una API de prueba que acepta cualquier POST y
+// descomprime el cuerpo según su Content-Encoding, para ver con -compress
+// cuántos bytes viajan y cuántos recibe el backend. Con -token exige ese
+// Bearer y entrega tokens en /oauth/token; con -secret verifica las firmas
+// HMAC:
+//
+//	go run ./cmd/mockapi -addr :8000
+//	go run ./cmd/mockapi -token demo -token-ttl 1m
+package main
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"flag"
+	"geova-simulation/simulation"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	token    = flag.String("token", "", "si no es vacío, Bearer que exigen los endpoints y que entrega /oauth/token")
+	tokenTTL = flag.Duration("token-ttl", time.Hour, "vigencia de los tokens de /oauth/token")
+	secret   = flag.String("secret", "", "si no es vacío, secreto con el que se verifican las firmas HMAC")
+)
+
+// maxSkew es la antigüedad máxima de la firma HMAC que se acepta.
+const maxSkew = 5 * time.Minute
+
+func main() {
+	addr := flag.String("addr", ":8000", "dirección donde escuchar")
+	flag.Parse()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", issueToken)
+	mux.HandleFunc("/", handle)
+	log.Printf("🧪 API de prueba en %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+// issueToken entrega -token a cualquier cliente que use client credentials.
+func issueToken(w http.ResponseWriter, r *http.Request) {
+	if *token == "" || r.FormValue("grant_type") != "client_credentials" {
+		http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+		return
+	}
+	client, _, _ := r.BasicAuth()
+	log.Printf("🔑 token para '%s' por %s", client, *tokenTTL)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": *token,
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenTTL.Seconds()),
+	})
+}
+
+func handle(w http.ResponseWriter, r *http.Request) {
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if *token != "" && r.Header.Get("Authorization") != "Bearer "+*token {
+		log.Printf("⛔ %s %s: token inválido", r.Method, r.URL.Path)
+		http.Error(w, `{"detail":"token inválido"}`, http.StatusUnauthorized)
+		return
+	}
+	if *secret != "" && !validSignature(r, raw) {
+		log.Printf("⛔ %s %s: firma inválida", r.Method, r.URL.Path)
+		http.Error(w, `{"detail":"firma inválida"}`, http.StatusUnauthorized)
+		return
+	}
+
+	encoding, err := simulation.ParseContentEncoding(r.Header.Get("Content-Encoding"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := simulation.Decompress(encoding, raw)
+	if err != nil {
+		log.Printf("❌ %s %s: %v", r.Method, r.URL.Path, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("📥 %s %s (%s): %d B en la red, %d B descomprimidos",
+		r.Method, r.URL.Path, r.Header.Get("Content-Type"), len(raw), len(body))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"received": len(body)})
+}
+
+// validSignature recalcula la firma sobre el cuerpo tal como llegó y
+// rechaza las de más de maxSkew.
+func validSignature(r *http.Request, raw []byte) bool {
+	ts := r.Header.Get(simulation.TimestampHeader)
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || time.Since(time.Unix(sec, 0)).Abs() > maxSkew {
+		return false
+	}
+	want := simulation.Sign(*secret, ts, r.Method, r.URL.Path, raw)
+	return hmac.Equal([]byte(want), []byte(r.Header.Get(simulation.SignatureHeader)))
+}
@@ -0,0 +1,64 @@
+// Command schema exporta los payloads de los sensores como JSON Schema, para
+// que el backend valide contra la misma versión que envía el simulador:
+//
+//	go run ./cmd/schema -version v2 -out schemas/v2
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"geova-simulation/simulation"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	version := flag.String("version", string(simulation.SchemaV1), "versión del schema (v1, v2) o archivo .json con un mapeo de campos")
+	kind := flag.String("kind", "", "tipo de payload a exportar (tfluna, mpu, imx); vacío: todos")
+	out := flag.String("out", "", "directorio donde escribir <tipo>.schema.json; vacío: stdout")
+	flag.Parse()
+
+	schema, err := simulation.ParseSchema(*version)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	kinds := simulation.PayloadKinds
+	if *kind != "" {
+		kinds = []string{*kind}
+	}
+
+	docs := make(map[string]json.RawMessage, len(kinds))
+	for _, k := range kinds {
+		doc, err := simulation.JSONSchema(k, schema)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		docs[k] = doc
+	}
+
+	if *out == "" {
+		if len(kinds) == 1 {
+			fmt.Println(string(docs[kinds[0]]))
+			return
+		}
+		data, err := json.MarshalIndent(docs, "", "  ")
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	for _, k := range kinds {
+		path := filepath.Join(*out, k+".schema.json")
+		if err := os.WriteFile(path, append(docs[k], '\n'), 0o644); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		log.Printf("📄 %s", path)
+	}
+}
@@ -0,0 +1,61 @@
+package codec
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Tipos mayores de CBOR (RFC 8949).
+const (
+	cborUint   = 0
+	cborNegint = 1
+	cborText   = 3
+	cborMap    = 5
+)
+
+// encodeCBOR escribe el mensaje como un map de CBOR de largo definido.
+func encodeCBOR(m Message) ([]byte, error) {
+	b := cborHead(nil, cborMap, uint64(len(m)))
+	for _, f := range m {
+		b = cborHead(b, cborText, uint64(len(f.Name)))
+		b = append(b, f.Name...)
+		switch v := f.Value.(type) {
+		case int:
+			if v >= 0 {
+				b = cborHead(b, cborUint, uint64(v))
+			} else {
+				b = cborHead(b, cborNegint, uint64(-1-v))
+			}
+		case float64:
+			b = binary.BigEndian.AppendUint64(append(b, 0xfb), math.Float64bits(v))
+		case bool:
+			if v {
+				b = append(b, 0xf5)
+			} else {
+				b = append(b, 0xf4)
+			}
+		case string:
+			b = cborHead(b, cborText, uint64(len(v)))
+			b = append(b, v...)
+		default:
+			return nil, unsupported(CBOR, f)
+		}
+	}
+	return b, nil
+}
+
+// cborHead escribe el tipo mayor y el argumento n en la forma más corta.
+func cborHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), n)
+}
@@ -0,0 +1,79 @@
+// Package codec serializa los payloads de los sensores en los formatos que
+// puede recibir el backend: JSON, MessagePack, CBOR y Protobuf. Los
+// codificadores son propios y cubren solo lo que usan los payloads (mensajes
+// planos de enteros, reales, booleanos y textos), así serializar no suma
+// dependencias. La compresión zstd de simulation sí usa una externa: a
+// diferencia de estos formatos, un compresor no es razonable escribirlo a
+// mano.
+package codec
+
+import (
+	"fmt"
+)
+
+// Format es un formato de serialización.
+type Format string
+
+const (
+	JSON     Format = "json"
+	MsgPack  Format = "msgpack"
+	CBOR     Format = "cbor"
+	Protobuf Format = "protobuf"
+)
+
+// Formats son los formatos soportados, en el orden en que se listan.
+var Formats = []Format{JSON, MsgPack, CBOR, Protobuf}
+
+// Parse valida el nombre de un formato.
+func Parse(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("formato desconocido '%s' (opciones: %v)", s, Formats)
+}
+
+// ContentType es el header Content-Type de las peticiones en el formato.
+func (f Format) ContentType() string {
+	switch f {
+	case MsgPack:
+		return "application/msgpack"
+	case CBOR:
+		return "application/cbor"
+	case Protobuf:
+		return "application/x-protobuf"
+	}
+	return "application/json"
+}
+
+// Field es un campo de un mensaje. Number es el número de campo en Protobuf;
+// los demás formatos usan Name. Value es int, float64, bool o string.
+type Field struct {
+	Name   string
+	Number int
+	Value  interface{}
+}
+
+// Message es un mensaje plano; los formatos con claves las escriben en este
+// orden.
+type Message []Field
+
+// Encode serializa m en el formato f.
+func Encode(f Format, m Message) ([]byte, error) {
+	switch f {
+	case JSON:
+		return encodeJSON(m)
+	case MsgPack:
+		return encodeMsgPack(m)
+	case CBOR:
+		return encodeCBOR(m)
+	case Protobuf:
+		return encodeProtobuf(m)
+	}
+	return nil, fmt.Errorf("formato desconocido '%s'", f)
+}
+
+func unsupported(f Format, field Field) error {
+	return fmt.Errorf("%s: el campo '%s' es de un tipo no soportado (%T)", f, field.Name, field.Value)
+}
@@ -0,0 +1,80 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+var sample = Message{
+	{Name: "a", Number: 1, Value: 1},
+	{Name: "b", Number: 2, Value: -3},
+	{Name: "c", Number: 3, Value: true},
+	{Name: "d", Number: 4, Value: "hi"},
+	{Name: "e", Number: 5, Value: 1.5},
+	{Name: "f", Number: 6, Value: 0},
+}
+
+// TestEncode compara contra bytes armados a mano con las especificaciones de
+// cada formato.
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSON, hex.EncodeToString([]byte(`{"a":1,"b":-3,"c":true,"d":"hi","e":1.5,"f":0}`))},
+		{MsgPack, "86" + "a161" + "01" + "a162" + "fd" + "a163" + "c3" + "a164" + "a26869" + "a165" + "cb3ff8000000000000" + "a166" + "00"},
+		{CBOR, "a6" + "6161" + "01" + "6162" + "22" + "6163" + "f5" + "6164" + "626869" + "6165" + "fb3ff8000000000000" + "6166" + "00"},
+		// proto3 no envía f = 0
+		{Protobuf, "0801" + "10fdffffffffffffffff01" + "1801" + "22026869" + "29000000000000f83f"},
+	}
+	for _, tt := range tests {
+		got, err := Encode(tt.format, sample)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("%s = %x, want %s", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeIntWidths(t *testing.T) {
+	tests := []struct {
+		v             int
+		msgpack, cbor string
+	}{
+		{127, "7f", "187f"},
+		{-32, "e0", "381f"},
+		{-33, "d0df", "3820"},
+		{200, "d100c8", "18c8"},
+		{5500, "d1157c", "19157c"},
+		{70000, "d200011170", "1a00011170"},
+	}
+	for _, tt := range tests {
+		m := Message{{Name: "v", Number: 1, Value: tt.v}}
+		mp, _ := Encode(MsgPack, m)
+		cb, _ := Encode(CBOR, m)
+		if got := hex.EncodeToString(mp[3:]); got != tt.msgpack {
+			t.Errorf("msgpack(%d) = %s, want %s", tt.v, got, tt.msgpack)
+		}
+		if got := hex.EncodeToString(cb[3:]); got != tt.cbor {
+			t.Errorf("cbor(%d) = %s, want %s", tt.v, got, tt.cbor)
+		}
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	if _, err := Encode(CBOR, Message{{Name: "x", Value: []int{1}}}); err == nil {
+		t.Error("CBOR aceptó un tipo no soportado")
+	}
+	if _, err := Encode(Protobuf, Message{{Name: "x", Value: 1}}); err == nil {
+		t.Error("Protobuf aceptó un campo sin número")
+	}
+	if _, err := Parse("xml"); err == nil {
+		t.Error(`Parse("xml") no falló`)
+	}
+	if got, _ := Encode(JSON, nil); !bytes.Equal(got, []byte("{}")) {
+		t.Errorf("JSON vacío = %s", got)
+	}
+}
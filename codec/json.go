@@ -0,0 +1,28 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// encodeJSON escribe los campos en orden, igual que json.Marshal con un
+// struct.
+func encodeJSON(m Message) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range m {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(f.Name)
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
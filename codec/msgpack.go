@@ -0,0 +1,65 @@
+package codec
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// encodeMsgPack escribe el mensaje como un map de MessagePack, con los
+// enteros en la representación más corta.
+func encodeMsgPack(m Message) ([]byte, error) {
+	var b []byte
+	if n := len(m); n < 16 {
+		b = append(b, 0x80|byte(n))
+	} else {
+		b = binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	}
+	for _, f := range m {
+		b = msgpackString(b, f.Name)
+		switch v := f.Value.(type) {
+		case int:
+			b = msgpackInt(b, int64(v))
+		case float64:
+			b = binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+		case bool:
+			if v {
+				b = append(b, 0xc3)
+			} else {
+				b = append(b, 0xc2)
+			}
+		case string:
+			b = msgpackString(b, v)
+		default:
+			return nil, unsupported(MsgPack, f)
+		}
+	}
+	return b, nil
+}
+
+func msgpackString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func msgpackInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0 && v < 128, v < 0 && v >= -32:
+		return append(b, byte(v)) // fixint positivo o negativo
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+}
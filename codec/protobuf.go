@@ -0,0 +1,53 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Tipos de cable de Protobuf.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// encodeProtobuf escribe el mensaje con la semántica de proto3: los campos
+// con el valor por defecto (0, false, "") no se envían. Los enteros son
+// int32 y los reales double, como en proto/geova.proto.
+func encodeProtobuf(m Message) ([]byte, error) {
+	var b []byte
+	for _, f := range m {
+		if f.Number <= 0 {
+			return nil, fmt.Errorf("%s: el campo '%s' no tiene número", Protobuf, f.Name)
+		}
+		tag := uint64(f.Number) << 3
+		switch v := f.Value.(type) {
+		case int:
+			if v != 0 {
+				b = binary.AppendUvarint(b, tag|wireVarint)
+				b = binary.AppendUvarint(b, uint64(int64(v)))
+			}
+		case float64:
+			if v != 0 {
+				b = binary.AppendUvarint(b, tag|wireFixed64)
+				b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+			}
+		case bool:
+			if v {
+				b = binary.AppendUvarint(b, tag|wireVarint)
+				b = append(b, 1)
+			}
+		case string:
+			if v != "" {
+				b = binary.AppendUvarint(b, tag|wireBytes)
+				b = binary.AppendUvarint(b, uint64(len(v)))
+				b = append(b, v...)
+			}
+		default:
+			return nil, unsupported(Protobuf, f)
+		}
+	}
+	return b, nil
+}
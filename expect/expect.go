@@ -0,0 +1,178 @@
+// Package expect evalúa expectativas sobre los paquetes de una corrida
+// (cuántos llegaron a Done, tasa de éxito, latencia p95, errores por
+// sensor) y las reporta como texto o JUnit XML, para usar el simulador como
+// paso de CI. Las usan los escenarios y el runner headless.
+package expect
+
+import (
+	"fmt"
+	"geova-simulation/pipeline"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expectation es una condición "<métrica> <op> <valor>", por ejemplo
+// "done >= 3", "success >= 95%", "p95 <= 2s" o "errors.tfluna == 0"; o la
+// forma especial "all done". La métrica puede llevar ".<sensor>" para
+// contar solo los paquetes de ese sensor.
+type Expectation struct {
+	Metric string
+	Sensor pipeline.Sensor // Vacío: todos los sensores
+	Op     string
+	Value  float64
+}
+
+type kind int
+
+const (
+	count kind = iota
+	ratio
+	duration
+)
+
+// metric calcula un valor sobre Stats; ok es false si no hay datos para
+// calcularlo (por ejemplo, latencias sin paquetes en Done).
+type metric struct {
+	kind  kind
+	value func(Stats) (v float64, ok bool)
+}
+
+var metrics = map[string]metric{
+	"packets":  {count, func(s Stats) (float64, bool) { return float64(s.Packets), true }},
+	"done":     {count, func(s Stats) (float64, bool) { return float64(s.Done), true }},
+	"errors":   {count, func(s Stats) (float64, bool) { return float64(s.Errors), true }},
+	"readings": {count, func(s Stats) (float64, bool) { return float64(s.Readings), true }},
+	"success":  {ratio, func(s Stats) (float64, bool) { return s.SuccessRatio(), s.Packets > 0 }},
+	"p50":      {duration, latency(0.50)},
+	"p95":      {duration, latency(0.95)},
+	"p99":      {duration, latency(0.99)},
+	"max":      {duration, latency(1)},
+}
+
+func latency(p float64) func(Stats) (float64, bool) {
+	return func(s Stats) (float64, bool) {
+		d, ok := s.Percentile(p)
+		return d.Seconds(), ok
+	}
+}
+
+var ops = map[string]func(a, b float64) bool{
+	">=": func(a, b float64) bool { return a >= b },
+	"<=": func(a, b float64) bool { return a <= b },
+	"==": func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+	">":  func(a, b float64) bool { return a > b },
+	"<":  func(a, b float64) bool { return a < b },
+}
+
+// Parse interpreta una expectativa escrita como texto ("done >= 3").
+func Parse(expr string) (Expectation, error) {
+	return ParseFields(strings.Fields(expr))
+}
+
+func ParseFields(fields []string) (Expectation, error) {
+	if len(fields) == 2 && fields[0] == "all" && fields[1] == "done" {
+		return Expectation{Metric: "all done"}, nil
+	}
+	if len(fields) != 3 {
+		return Expectation{}, fmt.Errorf("uso: all done | <métrica>[.<sensor>] <op> <valor>")
+	}
+
+	name, sensor, _ := strings.Cut(fields[0], ".")
+	m, ok := metrics[name]
+	if !ok {
+		return Expectation{}, fmt.Errorf("métrica desconocida '%s' (opciones: packets, done, errors, readings, success, p50, p95, p99, max)", name)
+	}
+	e := Expectation{Metric: name, Sensor: pipeline.Sensor(sensor), Op: fields[1]}
+	if sensor != "" && !knownSensor(e.Sensor) {
+		return Expectation{}, fmt.Errorf("sensor desconocido '%s'", sensor)
+	}
+	if _, ok := ops[e.Op]; !ok {
+		return Expectation{}, fmt.Errorf("operador desconocido '%s'", e.Op)
+	}
+
+	var err error
+	if e.Value, err = parseValue(m.kind, fields[2]); err != nil {
+		return Expectation{}, err
+	}
+	return e, nil
+}
+
+func knownSensor(s pipeline.Sensor) bool {
+	for _, sensor := range pipeline.AllSensors {
+		if sensor == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseValue lee cantidades, proporciones (0.95 o 95%) y duraciones ("2s",
+// guardadas en segundos).
+func parseValue(k kind, s string) (float64, error) {
+	switch k {
+	case ratio:
+		if pct, ok := strings.CutSuffix(s, "%"); ok {
+			v, err := strconv.ParseFloat(pct, 64)
+			if err != nil {
+				return 0, fmt.Errorf("porcentaje inválido '%s'", s)
+			}
+			return v / 100, nil
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < 0 || v > 1 {
+			return 0, fmt.Errorf("proporción inválida '%s' (entre 0 y 1, o porcentaje)", s)
+		}
+		return v, nil
+	case duration:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("duración inválida '%s'", s)
+		}
+		return d.Seconds(), nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("número inválido '%s'", s)
+	}
+	return float64(v), nil
+}
+
+func (e Expectation) String() string {
+	if e.Metric == "all done" {
+		return "all done"
+	}
+	name := e.Metric
+	if e.Sensor != "" {
+		name += "." + string(e.Sensor)
+	}
+	return fmt.Sprintf("%s %s %s", name, e.Op, format(metrics[e.Metric].kind, e.Value))
+}
+
+func format(k kind, v float64) string {
+	switch k {
+	case ratio:
+		return strconv.FormatFloat(math.Round(v*1000)/10, 'f', -1, 64) + "%"
+	case duration:
+		return time.Duration(v * float64(time.Second)).Round(time.Millisecond).String()
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Check evalúa la expectativa y retorna también el valor observado, para el
+// reporte.
+func (e Expectation) Check(rec *Recorder) (bool, string) {
+	s := rec.Stats(e.Sensor)
+	if e.Metric == "all done" {
+		return s.Packets > 0 && s.Done == s.Packets, fmt.Sprintf("%d de %d en Done", s.Done, s.Packets)
+	}
+
+	m := metrics[e.Metric]
+	v, ok := m.value(s)
+	if !ok {
+		return false, "sin datos"
+	}
+	return ops[e.Op](v, e.Value), e.Metric + " = " + format(m.kind, v)
+}
@@ -0,0 +1,128 @@
+package expect
+
+import (
+	"bytes"
+	"geova-simulation/state"
+	"strings"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// recorder arma una corrida con tres paquetes del TF-Luna en Done (1s, 2s y
+// 3s de latencia) y uno del MPU en Error.
+func recorder() *Recorder {
+	vs := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+	for i, id := range []string{"geova1/tfluna-1", "geova1/tfluna-2", "geova1/tfluna-3"} {
+		p := &state.PacketState{ID: id}
+		p.SetStatus(state.SendingToAPI, epoch)
+		p.SetStatus(state.Done, epoch.Add(time.Duration(i+1)*time.Second))
+		vs.Packets[id] = p
+	}
+	failed := &state.PacketState{ID: "geova1/mpu"}
+	failed.SetStatus(state.SendingToAPI, epoch)
+	failed.SetStatus(state.Error, epoch.Add(time.Second))
+	vs.Packets[failed.ID] = failed
+
+	rec := NewRecorder()
+	rec.Observe(vs)
+	return rec
+}
+
+func TestCheck(t *testing.T) {
+	rec := recorder()
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"packets == 4", true},
+		{"done >= 3", true},
+		{"readings == 3", true},
+		{"success >= 75%", true},
+		{"success >= 0.8", false},
+		{"errors.tfluna == 0", true},
+		{"errors.mpu == 0", false},
+		{"p50 <= 2s", true},
+		{"p95 <= 2s", false},
+		{"max == 3s", true},
+		{"p95.mpu <= 1s", false}, // Sin paquetes en Done: sin datos
+		{"all done", false},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if got, detail := e.Check(rec); got != tt.want {
+			t.Errorf("%s = %v (%s), want %v", tt.expr, got, detail, tt.want)
+		}
+	}
+}
+
+// TestRecorderEvictsFinished sigue un paquete del streaming hasta que el
+// pipeline lo descarta: se cuenta una sola vez y el Recorder no lo retiene.
+func TestRecorderEvictsFinished(t *testing.T) {
+	vs := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+	p := &state.PacketState{ID: "geova1/mpu-1"}
+	p.SetStatus(state.SendingToAPI, epoch)
+	vs.Packets[p.ID] = p
+
+	rec := NewRecorder()
+	rec.Observe(vs)
+	if s := rec.Stats(""); s.Packets != 1 || s.Done != 0 || len(rec.live) != 1 {
+		t.Fatalf("en vuelo: %+v, %d vivos", s, len(rec.live))
+	}
+
+	p.SetStatus(state.Done, epoch.Add(1500*time.Millisecond))
+	rec.Observe(vs)
+	rec.Observe(vs)
+	delete(vs.Packets, p.ID)
+	rec.Observe(vs)
+
+	if len(rec.live) != 0 || len(rec.recorded) != 0 {
+		t.Errorf("el Recorder retiene %d vivos y %d terminados", len(rec.live), len(rec.recorded))
+	}
+	s := rec.Stats("")
+	if s.Packets != 1 || s.Done != 1 || len(s.Latencies) != 1 || s.Latencies[0] != 1500*time.Millisecond {
+		t.Errorf("tras descartarlo: %+v, want 1 paquete en Done con 1.5s", s)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{"done >= 3 paquetes", "uso:"},
+		{"latency <= 2s", "métrica desconocida 'latency'"},
+		{"errors.lidar == 0", "sensor desconocido 'lidar'"},
+		{"done => 3", "operador desconocido '=>'"},
+		{"success >= 1.5", "proporción inválida"},
+		{"p95 <= 2", "duración inválida '2'"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.expr); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) = %v, want error con %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	results := []Result{
+		{Name: "done >= 3", Passed: true, Got: "done = 3"},
+		{Name: "p95 <= 2s", Got: "p95 = 3s"},
+	}
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, "basico", results, 1500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testsuite name="basico" tests="2" failures="1" time="1.500">`,
+		`<testcase name="done &gt;= 3" classname="basico"></testcase>`,
+		`<failure message="p95 = 3s">`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("falta %q en:\n%s", want, buf.String())
+		}
+	}
+}
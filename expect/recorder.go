@@ -0,0 +1,140 @@
+package expect
+
+import (
+	"geova-simulation/pipeline"
+	"geova-simulation/state"
+	"math"
+	"sort"
+	"time"
+)
+
+// Recorder junta los resultados de los paquetes que vio. Hay que llamar a
+// Observe en cada frame: Start vacía el mapa de paquetes y el streaming
+// descarta los terminados. Los paquetes en vuelo se siguen por puntero; al
+// terminar, su resultado pasa a finished y el puntero se suelta apenas el
+// paquete sale del estado, así una corrida larga de streaming no acumula
+// paquetes.
+type Recorder struct {
+	live     map[*state.PacketState]outcome
+	recorded map[*state.PacketState]bool // Terminados que siguen en el estado
+	finished []outcome
+}
+
+type outcome struct {
+	sensor   pipeline.Sensor
+	status   state.PacketStatus
+	readings int
+	body     int           // Bytes del cuerpo sin comprimir
+	sent     int           // Bytes que salieron a la red
+	latency  time.Duration // Desde que salió hasta Done
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		live:     make(map[*state.PacketState]outcome),
+		recorded: make(map[*state.PacketState]bool),
+	}
+}
+
+// Observe registra los paquetes actuales. Toma el mutex del estado.
+//
+// La latencia sale de las transiciones, que workers y FSM marcan con el reloj
+// del pipeline: es tiempo simulado, no de pared.
+func (r *Recorder) Observe(vs *state.VisualState) {
+	vs.Mutex.Lock()
+	defer vs.Mutex.Unlock()
+	for _, packet := range vs.Packets {
+		if r.recorded[packet] {
+			continue
+		}
+		o := outcome{sensor: pipeline.SensorOf(packet.ID), status: packet.Status, readings: max(packet.Readings, 1),
+			body: len(packet.Body), sent: packet.SentBytes}
+		if packet.Status != state.Done && packet.Status != state.Error {
+			r.live[packet] = o
+			continue
+		}
+		if n := len(packet.Transitions); packet.Status == state.Done && n > 0 {
+			o.latency = packet.Transitions[n-1].At.Sub(packet.Transitions[0].At)
+		}
+		r.finished = append(r.finished, o)
+		r.recorded[packet] = true
+		delete(r.live, packet)
+	}
+
+	// Suelta los paquetes que ya no están en el estado
+	for packet, o := range r.live {
+		if vs.Packets[packet.ID] != packet {
+			// Se fue sin terminar (Start vació el mapa): queda como estaba
+			r.finished = append(r.finished, o)
+			delete(r.live, packet)
+		}
+	}
+	for packet := range r.recorded {
+		if vs.Packets[packet.ID] != packet {
+			delete(r.recorded, packet)
+		}
+	}
+}
+
+// Stats resume los paquetes vistos; con sensor distinto de "" solo cuenta
+// los de ese sensor.
+func (r *Recorder) Stats(sensor pipeline.Sensor) Stats {
+	var s Stats
+	add := func(o outcome) {
+		if sensor != "" && o.sensor != sensor {
+			return
+		}
+		s.Packets++
+		s.BodyBytes += o.body
+		s.SentBytes += o.sent
+		switch o.status {
+		case state.Done:
+			s.Done++
+			s.Readings += o.readings
+			s.Latencies = append(s.Latencies, o.latency)
+		case state.Error:
+			s.Errors++
+		}
+	}
+	for _, o := range r.finished {
+		add(o)
+	}
+	for _, o := range r.live {
+		add(o)
+	}
+	sort.Slice(s.Latencies, func(i, j int) bool { return s.Latencies[i] < s.Latencies[j] })
+	return s
+}
+
+// Stats son los totales de una corrida. Readings cuenta las lecturas de los
+// paquetes en Done (un lote lleva varias) y Latencies son las de esos
+// paquetes, ordenadas. BodyBytes y SentBytes suman los cuerpos de todos los
+// paquetes antes y después de comprimirlos.
+type Stats struct {
+	Packets, Done, Errors int
+	Readings              int
+	BodyBytes, SentBytes  int
+	Latencies             []time.Duration
+}
+
+// SuccessRatio es la fracción de paquetes que llegaron a Done.
+func (s Stats) SuccessRatio() float64 {
+	if s.Packets == 0 {
+		return 0
+	}
+	return float64(s.Done) / float64(s.Packets)
+}
+
+// Percentile retorna la latencia del percentil p (0 a 1) por rango más
+// cercano; ok es false si no hay paquetes en Done.
+func (s Stats) Percentile(p float64) (time.Duration, bool) {
+	n := len(s.Latencies)
+	if n == 0 {
+		return 0, false
+	}
+	i := int(math.Ceil(p*float64(n))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return s.Latencies[i], true
+}
@@ -0,0 +1,107 @@
+package expect
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Result es el resultado de una expectativa o de otra verificación de la
+// corrida (por ejemplo, que terminó antes del tiempo máximo).
+type Result struct {
+	Name   string
+	Passed bool
+	Got    string
+}
+
+// Evaluate comprueba cada expectativa contra lo que vio rec.
+func Evaluate(expectations []Expectation, rec *Recorder) []Result {
+	results := make([]Result, len(expectations))
+	for i, e := range expectations {
+		passed, got := e.Check(rec)
+		results[i] = Result{Name: e.String(), Passed: passed, Got: got}
+	}
+	return results
+}
+
+// Passed indica si se cumplieron todos los resultados.
+func Passed(results []Result) bool {
+	for _, res := range results {
+		if !res.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Report escribe una línea por resultado y retorna si se cumplieron todos.
+func Report(w io.Writer, name string, results []Result) bool {
+	fmt.Fprintf(w, "--- Expectativas: %s ---\n", name)
+	for _, res := range results {
+		mark := "OK   "
+		if !res.Passed {
+			mark = "FALLA"
+		}
+		fmt.Fprintf(w, "  %s  %-24s (%s)\n", mark, res.Name, res.Got)
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(w, "  (sin expectativas)")
+	}
+	return Passed(results)
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit escribe los resultados como un testsuite JUnit XML, el formato
+// que entienden GitHub Actions, GitLab y Jenkins. elapsed es la duración de
+// la corrida.
+func WriteJUnit(w io.Writer, suite string, results []Result, elapsed time.Duration) error {
+	s := junitSuite{
+		Name:  suite,
+		Tests: len(results),
+		Time:  strconv.FormatFloat(elapsed.Seconds(), 'f', 3, 64),
+	}
+	for _, res := range results {
+		c := junitCase{Name: res.Name, Classname: suite}
+		if !res.Passed {
+			s.Failures++
+			c.Failure = &junitFailure{Message: res.Got, Text: fmt.Sprintf("%s: se obtuvo %s", res.Name, res.Got)}
+		}
+		s.Cases = append(s.Cases, c)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{s}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"geova-simulation/i18n"
+	"geova-simulation/state"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	chartCols    = 2
+	chartPadding = 8.0
+	// Alto de las dos líneas de texto (título y min/max/prom) sobre cada gráfica.
+	chartHeaderH = 30.0
+)
+
+// metricKeys son las claves del catálogo para el título de cada métrica.
+var metricKeys = [state.MetricCount]string{
+	state.MetricDistancia:   "metric.distance",
+	state.MetricRoll:        "metric.roll",
+	state.MetricPitch:       "metric.pitch",
+	state.MetricNitidez:     "metric.sharpness",
+	state.MetricFuerzaSenal: "metric.signal",
+	state.MetricTemperatura: "metric.temperature",
+}
+
+var (
+	chartPanelColor = color.RGBA{R: 0x10, G: 0x10, B: 0x18, A: 220}
+	chartFrameColor = color.RGBA{R: 90, G: 90, B: 110, A: 255}
+	chartLineColor  = color.RGBA{R: 80, G: 220, B: 255, A: 255}
+	chartAvgColor   = color.RGBA{R: 255, G: 200, B: 50, A: 160}
+)
+
+// drawCharts dibuja una gráfica por métrica del historial en una grilla que
+// se adapta al tamaño del panel.
+func (g *Game) drawCharts(screen *ebiten.Image) {
+	g.State.Mutex.Lock()
+	defer g.State.Mutex.Unlock()
+
+	px, py := float32(g.scene.Charts.X), float32(g.scene.Charts.Y)
+	pw, ph := float32(g.chartW), float32(g.chartH)
+	vector.FillRect(screen, px, py, pw, ph, chartPanelColor, false)
+	vector.StrokeRect(screen, px, py, pw, ph, 1, chartFrameColor, false)
+
+	// Agarre para redimensionar
+	grip := float32(chartGripSize)
+	vector.StrokeLine(screen, px+pw-grip, py+ph, px+pw, py+ph-grip, 1, chartFrameColor, true)
+	vector.StrokeLine(screen, px+pw-grip/2, py+ph, px+pw, py+ph-grip/2, 1, chartFrameColor, true)
+
+	// Las gráficas siguen al dispositivo seleccionado
+	d := g.State.SelectedDevice()
+	if d == nil {
+		return
+	}
+	rows := (int(state.MetricCount) + chartCols - 1) / chartCols
+	cellW := (g.chartW - chartPadding) / chartCols
+	cellH := (g.chartH - chartPadding) / float64(rows)
+
+	for m := state.Metric(0); m < state.MetricCount; m++ {
+		col, row := int(m)%chartCols, int(m)/chartCols
+		x := g.scene.Charts.X + chartPadding + float64(col)*cellW
+		y := g.scene.Charts.Y + chartPadding + float64(row)*cellH
+		drawChart(screen, i18n.T(metricKeys[m]), &d.History[m],
+			x, y, cellW-chartPadding, cellH-chartPadding)
+	}
+}
+
+// drawChart dibuja una serie como línea en el rectángulo dado. El eje X
+// cubre toda la capacidad del buffer, así la línea avanza de derecha a
+// izquierda a medida que llegan muestras.
+func drawChart(screen *ebiten.Image, title string, s *state.Series, x, y, w, h float64) {
+	drawText(screen, title, int(x), int(y))
+
+	min, max, avg, ok := s.Stats()
+	if !ok {
+		drawText(screen, i18n.T("chart.no_data"), int(x), int(y)+14)
+		return
+	}
+	drawText(screen,
+		i18n.T("chart.stats", min, max, avg), int(x), int(y)+14)
+
+	plotY, plotH := y+chartHeaderH, h-chartHeaderH
+	if plotH <= 0 {
+		return
+	}
+	vector.StrokeRect(screen, float32(x), float32(plotY), float32(w), float32(plotH), 1, chartFrameColor, false)
+
+	lo, hi := min, max
+	if hi == lo {
+		lo, hi = lo-1, hi+1
+	}
+	toY := func(v float64) float32 {
+		return float32(plotY + plotH - (v-lo)/(hi-lo)*plotH)
+	}
+
+	avgY := toY(avg)
+	vector.StrokeLine(screen, float32(x), avgY, float32(x+w), avgY, 1, chartAvgColor, false)
+
+	values := s.Values()
+	step := w / float64(s.Cap()-1)
+	startX := x + w - float64(len(values)-1)*step
+	for i := 1; i < len(values); i++ {
+		x0 := float32(startX + float64(i-1)*step)
+		x1 := float32(startX + float64(i)*step)
+		vector.StrokeLine(screen, x0, toY(values[i-1]), x1, toY(values[i]), 1.5, chartLineColor, true)
+	}
+	if len(values) == 1 {
+		vector.FillCircle(screen, float32(x+w), toY(values[0]), 2, chartLineColor, true)
+	}
+}
@@ -1,29 +1,24 @@
 package game
 
 const (
-	tripodeX = 80.0
-	tripodeY = 200.0
+	timeScaleMin  = 0.25
+	timeScaleMax  = 8.0
+	sliderWidth   = 180.0
+	sliderKnobRad = 6.0
 
-	iconPythonX    = 250.0
-	iconPythonY    = 200.0
-	iconRabbitX    = 400.0
-	iconRabbitY    = 200.0
-	iconWebsocketX = 550.0
-	iconWebsocketY = 200.0
+	maxTilt = 15.0
 
-	monitorX = 620.0
-	monitorY = 180.0
+	// Nivel de burbuja
+	levelRadius    = 24.0
+	levelBubbleRad = 5.0
+	levelTolerance = 1.0 // Grados que se consideran "nivelado"
 
-	tiltMeterX = 100.0
-	tiltMeterY = 50.0
+	// Largo máximo del motivo que se muestra junto a "× ERROR"
+	errorReasonChars = 32
 
-	dashboardX = 50.0
-	dashboardY = 450.0
-
-	packetSpeed     = 3.0
-	processingDelay = 30
-
-	tripodeFrameWidth  = 128
-	tripodeFrameHeight = 128
-	tripodeFrameCount  = 7
+	chartDefaultW = 420.0
+	chartDefaultH = 300.0
+	chartMinW     = 240.0
+	chartMinH     = 180.0
+	chartGripSize = 12.0
 )
@@ -0,0 +1,63 @@
+package game
+
+import (
+	"geova-simulation/assets"
+	"geova-simulation/hotreload"
+	"io/fs"
+	"log"
+	"path/filepath"
+)
+
+// devReload recarga sprites y layout mientras el programa corre (-dev).
+type devReload struct {
+	watcher    *hotreload.Watcher
+	assetsFS   fs.FS
+	layoutPath string
+}
+
+// EnableDevReload hace que cada Update aplique los cambios que detecte w:
+// si cambió layoutPath se relee el layout, y cualquier otro archivo recarga
+// los assets desde assetsFS.
+func (g *Game) EnableDevReload(w *hotreload.Watcher, assetsFS fs.FS, layoutPath string) {
+	g.dev = &devReload{watcher: w, assetsFS: assetsFS, layoutPath: layoutPath}
+}
+
+// applyDevChanges corre en el goroutine del game loop, así los sprites y el
+// layout nunca cambian en medio de un Draw.
+func (g *Game) applyDevChanges() {
+	changes := g.dev.watcher.Changes()
+	if changes == nil {
+		return
+	}
+
+	reloadAssets := false
+	for _, path := range changes {
+		if g.dev.layoutPath != "" && filepath.Clean(path) == filepath.Clean(g.dev.layoutPath) {
+			g.reloadLayout()
+		} else {
+			reloadAssets = true
+		}
+	}
+
+	if reloadAssets {
+		fresh, err := assets.LoadAssets(g.dev.assetsFS)
+		if err != nil {
+			log.Printf("♻️  Recarga de assets fallida, se mantienen los anteriores: %v", err)
+			return
+		}
+		*g.Assets = *fresh
+		// El botón puede haber cambiado de tamaño
+		g.applyLayout()
+		log.Printf("♻️  Assets recargados (%d cambios)", len(changes))
+	}
+}
+
+func (g *Game) reloadLayout() {
+	layout, err := LoadLayout(g.dev.layoutPath)
+	if err != nil {
+		log.Printf("♻️  Layout inválido, se mantiene el anterior: %v", err)
+		return
+	}
+	g.SetLayout(layout)
+	log.Println("♻️  Layout recargado")
+}
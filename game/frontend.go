@@ -0,0 +1,162 @@
+package game
+
+import (
+	"geova-simulation/i18n"
+	"geova-simulation/state"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// La aguja recorre 270° de la carátula: ±maxTilt quedan a ±135° de la
+	// vertical.
+	gaugeSweep = 3 * math.Pi / 4
+
+	// Rango de distancia del TF-Luna que se proyecta en la cámara.
+	laserMinDist = 1.5
+	laserMaxDist = 3.0
+	laserMargin  = 8.0
+
+	// El monitor del frontend se dibuja reducido; su pantalla ocupa este
+	// rectángulo dentro del sprite original.
+	frontendScale   = 0.75
+	frontendScreenX = 8
+	frontendScreenY = 12
+	frontendScreenW = 240
+	frontendScreenH = 170
+)
+
+// drawFrontend dibuja lo que vería el usuario de GEOVA con los últimos datos
+// del dispositivo seleccionado: el medidor de roll, la cámara y el resumen.
+func (g *Game) drawFrontend(screen *ebiten.Image) {
+	g.State.Mutex.Lock()
+	defer g.State.Mutex.Unlock()
+
+	d := g.State.SelectedDevice()
+	if d == nil {
+		return
+	}
+	g.drawGauge(screen, d)
+	g.drawCamera(screen, d)
+	g.drawFrontendMonitor(screen, d)
+}
+
+func (g *Game) drawGauge(screen *ebiten.Image, d *state.DeviceState) {
+	bg, needle := g.Assets.UIGaugeBG, g.Assets.UIGaugeNeedle
+	x, y := g.scene.Gauge.X, g.scene.Gauge.Y
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(bg.FrameAt(g.animTime), op)
+
+	// El pivote de la aguja es su extremo izquierdo, al centro de la carátula.
+	op = &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(0, -float64(needle.FrameHeight)/2)
+	op.GeoM.Rotate(needleAngle(d.DisplayRoll))
+	op.GeoM.Translate(x+float64(bg.FrameWidth)/2, y+float64(bg.FrameHeight)/2)
+	screen.DrawImage(needle.FrameAt(g.animTime), op)
+
+	drawText(screen,
+		i18n.T("frontend.roll", d.DisplayRoll),
+		int(x)+20, int(y)+bg.FrameHeight+4)
+}
+
+// needleAngle convierte el roll en el ángulo de la aguja (radianes, 0 apunta
+// a la derecha como el sprite).
+func needleAngle(roll float64) float64 {
+	roll = math.Max(-maxTilt, math.Min(maxTilt, roll))
+	return -math.Pi/2 + roll/maxTilt*gaugeSweep
+}
+
+func (g *Game) drawCamera(screen *ebiten.Image, d *state.DeviceState) {
+	cam := g.Assets.CameraView
+	x, y := g.scene.Camera.X, g.scene.Camera.Y
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(cam.FrameAt(g.animTime), op)
+
+	label := i18n.T("frontend.laser_na")
+	if d.DisplayLaser {
+		label = i18n.T("frontend.laser_yes")
+		dot := g.Assets.LaserDot
+		dx, dy := laserDotPosition(d.DisplayRoll, d.DisplayDistancia,
+			float64(cam.FrameWidth), float64(cam.FrameHeight))
+
+		op = &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(x+dx-float64(dot.FrameWidth)/2, y+dy-float64(dot.FrameHeight)/2)
+		screen.DrawImage(dot.FrameAt(g.animTime), op)
+	} else if d.DisplayNitidez > 0 {
+		label = i18n.T("frontend.laser_no")
+	}
+	drawText(screen, label, int(x), int(y)+cam.FrameHeight+4)
+}
+
+// laserDotPosition ubica el punto del láser dentro de una vista de w×h: el
+// roll lo desplaza en horizontal y la distancia lo acerca al horizonte (la
+// mitad de la imagen) cuanto más lejos está el objetivo.
+func laserDotPosition(roll, distancia, w, h float64) (float64, float64) {
+	roll = math.Max(-maxTilt, math.Min(maxTilt, roll))
+	x := w/2 + roll/maxTilt*(w/2-laserMargin)
+
+	if distancia <= 0 {
+		return x, h / 2
+	}
+	far := (distancia - laserMinDist) / (laserMaxDist - laserMinDist)
+	far = math.Max(0, math.Min(1, far))
+	y := h/2 + (1-far)*(h/2-laserMargin)
+	return x, y
+}
+
+func (g *Game) drawFrontendMonitor(screen *ebiten.Image, d *state.DeviceState) {
+	x, y := g.scene.Frontend.X, g.scene.Frontend.Y
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(frontendScale, frontendScale)
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(g.Assets.FrontendMonitor.FrameAt(g.animTime), op)
+
+	// El texto es blanco, así que se oscurece la pantalla.
+	sx := float32(x + frontendScreenX*frontendScale)
+	sy := float32(y + frontendScreenY*frontendScale)
+	vector.FillRect(screen, sx, sy, frontendScreenW*frontendScale, frontendScreenH*frontendScale,
+		color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 255}, false)
+
+	// Réplica del trípode con el último roll recibido.
+	tripod := g.Assets.GeovaTripod
+	const tripodScale = 0.4
+	half := float64(tripod.FrameWidth) * tripodScale / 2
+	op = &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-float64(tripod.FrameWidth)/2, -float64(tripod.FrameHeight)/2)
+	op.GeoM.Rotate(d.DisplayRoll * math.Pi / 180)
+	op.GeoM.Scale(tripodScale, tripodScale)
+	op.GeoM.Translate(float64(sx)+4+half, float64(sy)+4+half)
+	screen.DrawImage(tripod.FrameAt(g.animTime), op)
+
+	lines := []string{"GEOVA", "", "", "", ""}
+	if len(g.State.Devices) > 1 {
+		lines[0] = d.Name
+	}
+	if d.DisplayDistancia > 0 {
+		lines[1] = i18n.T("frontend.distance", d.DisplayDistancia)
+	}
+	if d.DisplayRoll != 0 || d.DisplayPitch != 0 {
+		lines[2] = i18n.T("frontend.angles", d.DisplayRoll, d.DisplayPitch)
+	}
+	if d.DisplayNitidez > 0 {
+		lines[3] = i18n.T("frontend.sharpness", d.DisplayNitidez)
+		lines[4] = i18n.T("frontend.dot_no")
+		if d.DisplayLaser {
+			lines[4] = i18n.T("frontend.dot_yes")
+		}
+	}
+	textX := int(sx) + int(2*half) + 10
+	for i, line := range lines {
+		if line != "" {
+			drawText(screen, line, textX, int(sy)+4+i*16)
+		}
+	}
+}
@@ -2,39 +2,125 @@ package game
 
 import (
 	"geova-simulation/assets"
+	"geova-simulation/expect"
+	"geova-simulation/pipeline"
+	"geova-simulation/scenario"
 	"geova-simulation/state"
 	"image"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
 )
 
 type Game struct {
-	Assets *assets.Assets
-	State  *state.VisualState
+	Assets   *assets.Assets
+	State    *state.VisualState
+	Pipeline *pipeline.Pipeline
 
 	BotonRect      image.Rectangle
 	isBotonPressed bool
 
-	animPacketCounter int
-	animIconCounter   int
+	// layout son las posiciones con anclas; scene, las mismas resueltas para
+	// el tamaño actual (screenW×screenH) de la escena.
+	layout           Layout
+	scene            scene
+	screenW, screenH int
+	dev              *devReload
+
+	// Tiempo simulado acumulado; las animaciones de los sprites lo siguen,
+	// así se congelan en pausa y respetan la escala de tiempo.
+	animTime time.Duration
+
+	paused         bool
+	stepFrame      bool
+	timeScale      float64
+	draggingSlider bool
+
+	// Arrastre del trípode para inclinarlo
+	draggingTripod bool
+	dragX, dragY   int
+
+	// Panel de gráficas (tecla G); se redimensiona desde la esquina.
+	showCharts     bool
+	chartW, chartH float64
+	resizingCharts bool
+
+	// Inspector de paquetes (tecla I o click en un paquete)
+	showInspector bool
+	inspectedID   string
+	inspectorMsg  string
+
+	// Escenario en ejecución (-scenario); avanza con el tiempo simulado.
+	scenario         *scenario.Runner
+	scenarioReported bool
 }
 
-func NewGame(assets *assets.Assets, state *state.VisualState, btnRect image.Rectangle) *Game {
-	return &Game{
+func NewGame(assets *assets.Assets, pipe *pipeline.Pipeline) *Game {
+	g := &Game{
 		Assets:    assets,
-		State:     state,
-		BotonRect: btnRect,
+		State:     pipe.State,
+		Pipeline:  pipe,
+		screenW:   designW,
+		screenH:   designH,
+		timeScale: 1.0,
+		chartW:    chartDefaultW,
+		chartH:    chartDefaultH,
 	}
+	g.SetLayout(DefaultLayout())
+	return g
 }
 
 func (g *Game) Update() error {
-	g.animPacketCounter = (g.animPacketCounter + 1) % 360
-	g.animIconCounter = (g.animIconCounter + 1) % 360
-
+	if g.dev != nil {
+		g.applyDevChanges()
+	}
 	g.handleInput()
-	g.updatePacketFSM()
+
+	if g.paused {
+		// El reloj sigue corriendo; Tick(0) descarta ese tiempo para que al
+		// reanudar no se aplique de golpe.
+		g.Pipeline.Tick(0)
+		if !g.stepFrame {
+			return nil
+		}
+		g.stepFrame = false
+		dt := time.Duration(float64(time.Second) / float64(ebiten.TPS()) * g.timeScale)
+		g.Pipeline.Step(dt)
+		g.advance(dt)
+	} else {
+		g.advance(g.Pipeline.Tick(g.timeScale))
+	}
 
 	return nil
 }
 
+// advance avanza las animaciones y el escenario dt de tiempo simulado.
+func (g *Game) advance(dt time.Duration) {
+	g.animTime += dt
+	if g.scenario == nil {
+		return
+	}
+	g.scenario.Advance(dt)
+	if !g.scenarioReported && g.scenario.Finished() {
+		g.scenarioReported = true
+		expect.Report(log.Writer(), g.scenario.Scenario.Name, g.scenario.Results())
+	}
+}
+
+// RunScenario ejecuta el escenario desde el próximo frame.
+func (g *Game) RunScenario(r *scenario.Runner) {
+	g.scenario = r
+	g.scenarioReported = false
+}
+
+// Layout adapta la escena al tamaño de la ventana; si cambió, vuelve a
+// resolver las anclas.
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return 900, 650
+	w, h := sceneSize(outsideWidth, outsideHeight)
+	if w != g.screenW || h != g.screenH {
+		g.screenW, g.screenH = w, h
+		g.applyLayout()
+	}
+	return w, h
 }
@@ -1,10 +1,8 @@
 package game
 
 import (
-	"geova-simulation/simulation"
-	"geova-simulation/state"
 	"image"
-	"image/color"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -15,57 +13,110 @@ func (g *Game) handleInput() {
 		ebiten.SetFullscreen(!ebiten.IsFullscreen())
 	}
 
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		g.paused = !g.paused
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyN) && g.paused {
+		g.stepFrame = true
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		g.toggleStreaming()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		g.showCharts = !g.showCharts
+	}
+
 	x, y := ebiten.CursorPosition()
 	clickPoint := image.Pt(x, y)
 
+	// Con el inspector abierto los clicks no llegan a la escena
+	if g.handleInspector(x, y) {
+		return
+	}
+
+	g.handleTimeSlider(x, y)
+	g.handleChartResize(x, y)
+
 	g.isBotonPressed = g.BotonRect.Bounds().Canon().Overlaps(
 		image.Rectangle{Min: clickPoint, Max: clickPoint.Add(image.Pt(1, 1))},
 	) && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
 
-	if ebiten.IsKeyPressed(ebiten.KeyLeft) && g.State.CurrentTilt > -15.0 {
-		g.State.CurrentTilt -= 0.5
-	}
-	if ebiten.IsKeyPressed(ebiten.KeyRight) && g.State.CurrentTilt < 15.0 {
-		g.State.CurrentTilt += 0.5
-	}
+	g.handleTilt(x, y)
 
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && !g.State.SimulacionIniciada {
 		if g.BotonRect.Bounds().Canon().Overlaps(
 			image.Rectangle{Min: clickPoint, Max: clickPoint.Add(image.Pt(1, 1))},
 		) {
-			g.startSimulation()
+			g.Pipeline.Start()
 		}
 	}
 }
 
-func (g *Game) startSimulation() {
+// toggleStreaming inicia o detiene el modo streaming. No arranca mientras
+// hay una simulación normal en curso.
+func (g *Game) toggleStreaming() {
 	g.State.Mutex.Lock()
+	streaming, running := g.State.Streaming, g.State.SimulacionIniciada
+	g.State.Mutex.Unlock()
 
-	g.State.Packets = make(map[string]*state.PacketState)
-	g.State.DisplayDistancia = 0
-	g.State.DisplayNitidez = 0
-	g.State.DisplayRoll = 0
-	g.State.SimulacionIniciada = true
-	g.State.PythonAPITimer = 0
-	g.State.RabbitMQTimer = 0
-	g.State.WebsocketAPITimer = 0
+	if streaming {
+		g.Pipeline.StopStreaming()
+	} else if !running {
+		g.Pipeline.StartStreaming()
+	}
+}
 
-	tilt := g.State.CurrentTilt
-	g.State.Mutex.Unlock()
+// handleTimeSlider arrastra el control de escala de tiempo. La escala es
+// logarítmica para que 1x quede en una posición útil entre 0.25x y 8x.
+func (g *Game) handleTimeSlider(x, y int) {
+	sliderX, sliderY := g.scene.TimeSlider.X, g.scene.TimeSlider.Y
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		fx, fy := float64(x), float64(y)
+		g.draggingSlider = fx >= sliderX-sliderKnobRad && fx <= sliderX+sliderWidth+sliderKnobRad &&
+			math.Abs(fy-sliderY) <= sliderKnobRad*2
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		g.draggingSlider = false
+	}
+	if g.draggingSlider {
+		pos := (float64(x) - sliderX) / sliderWidth
+		g.timeScale = timeScaleFromSlider(math.Max(0, math.Min(1, pos)))
+	}
+}
+
+// handleChartResize cambia el tamaño del panel de gráficas arrastrando el
+// agarre de la esquina inferior derecha.
+func (g *Game) handleChartResize(x, y int) {
+	if !g.showCharts {
+		g.resizingCharts = false
+		return
+	}
+	px, py := g.scene.Charts.X, g.scene.Charts.Y
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		gx, gy := px+g.chartW, py+g.chartH
+		fx, fy := float64(x), float64(y)
+		g.resizingCharts = fx >= gx-chartGripSize && fx <= gx && fy >= gy-chartGripSize && fy <= gy
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		g.resizingCharts = false
+	}
+	if g.resizingCharts {
+		w, h := g.screenW, g.screenH
+		g.chartW = math.Max(chartMinW, math.Min(float64(w)-px, float64(x)-px))
+		g.chartH = math.Max(chartMinH, math.Min(float64(h)-py, float64(y)-py))
+	}
+}
+
+// chartsContain indica si el punto cae sobre el panel de gráficas visible.
+func (g *Game) chartsContain(x, y float64) bool {
+	px, py := g.scene.Charts.X, g.scene.Charts.Y
+	return g.showCharts && x >= px && x < px+g.chartW && y >= py && y < py+g.chartH
+}
+
+func timeScaleFromSlider(pos float64) float64 {
+	return timeScaleMin * math.Pow(timeScaleMax/timeScaleMin, pos)
+}
 
-	go simulation.SendPOSTRequest(
-		"http://localhost:8000/tfluna/sensor",
-		simulation.GenerateRandomTFLunaData(),
-		"tfluna", g.State, 180.0, color.RGBA{R: 255, G: 50, B: 50, A: 255},
-	)
-	go simulation.SendPOSTRequest(
-		"http://localhost:8000/mpu/sensor",
-		simulation.GenerateRandomMPUData(tilt),
-		"mpu", g.State, 200.0, color.RGBA{R: 50, G: 150, B: 255, A: 255},
-	)
-	go simulation.SendPOSTRequest(
-		"http://localhost:8000/imx477/sensor",
-		simulation.GenerateRandomIMXData(),
-		"imx", g.State, 220.0, color.RGBA{R: 50, G: 255, B: 50, A: 255},
-	)
+func sliderFromTimeScale(scale float64) float64 {
+	return math.Log(scale/timeScaleMin) / math.Log(timeScaleMax/timeScaleMin)
 }
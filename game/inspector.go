@@ -0,0 +1,258 @@
+package game
+
+import (
+	"fmt"
+	"geova-simulation/i18n"
+	"geova-simulation/inspector"
+	"image/color"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	inspectorW       = 860.0
+	inspectorH       = 540.0
+	inspectorListW   = 250.0 // IDs con dispositivo ("geova2/tfluna") y estado
+	inspectorListTop = 30.0  // Debajo del título
+	inspectorLineH   = 16
+	inspectorChars   = 80 // Caracteres por línea en la columna de detalle (monoespaciada)
+	packetSize       = 32.0
+)
+
+var inspectorColor = color.RGBA{R: 0x0c, G: 0x10, B: 0x1c, A: 235}
+
+// packetIDs retorna los IDs de los paquetes ordenados. Requiere el mutex.
+func (g *Game) packetIDs() []string {
+	ids := make([]string, 0, len(g.State.Packets))
+	for id := range g.State.Packets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// handleInspector procesa la entrada del inspector. Retorna true si el
+// inspector está abierto y consumió la entrada.
+func (g *Game) handleInspector(x, y int) bool {
+	if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+		g.showInspector = !g.showInspector
+		g.inspectorMsg = ""
+	}
+	clicked := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+
+	if !g.showInspector {
+		if clicked {
+			if id := g.packetAt(float64(x), float64(y)); id != "" {
+				g.openInspector(id)
+				return true
+			}
+		}
+		return false
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.showInspector = false
+		return true
+	}
+	if clicked {
+		g.State.Mutex.Lock()
+		ids := g.packetIDs()
+		g.State.Mutex.Unlock()
+
+		ix, iy := g.inspectorOrigin()
+		top := iy + inspectorListTop
+		fx, fy := float64(x), float64(y)
+		if fx >= ix && fx < ix+inspectorListW {
+			i := int(fy-top) / inspectorLineH
+			if fy >= top && i < len(ids) {
+				g.inspectedID = ids[i]
+				g.inspectorMsg = ""
+			}
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		g.copyInspected()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		g.dumpInspected()
+	}
+	return true
+}
+
+// inspectorOrigin centra el inspector en la escena.
+func (g *Game) inspectorOrigin() (float64, float64) {
+	return math.Round((float64(g.screenW) - inspectorW) / 2), math.Round((float64(g.screenH) - inspectorH) / 2)
+}
+
+func (g *Game) openInspector(id string) {
+	g.showInspector = true
+	g.inspectedID = id
+	g.inspectorMsg = ""
+}
+
+// packetAt retorna el ID del paquete activo bajo el cursor, o "".
+func (g *Game) packetAt(x, y float64) string {
+	g.State.Mutex.Lock()
+	defer g.State.Mutex.Unlock()
+
+	for _, id := range g.packetIDs() {
+		p := g.State.Packets[id]
+		// Mismo recuadro que drawPackets: los lotes crecen alrededor del centro
+		scale := packetScale(p)
+		left, top := p.X-packetSize*(scale-1)/2, p.Y-packetSize*(scale-1)/2
+		if p.Active && x >= left && x < left+packetSize*scale && y >= top && y < top+packetSize*scale {
+			return id
+		}
+	}
+	return ""
+}
+
+// inspected copia el paquete seleccionado; ok es false si ya no existe.
+func (g *Game) inspected() (inspector.Record, bool) {
+	g.State.Mutex.Lock()
+	defer g.State.Mutex.Unlock()
+
+	p, ok := g.State.Packets[g.inspectedID]
+	if !ok {
+		return inspector.Record{}, false
+	}
+	return inspector.Snapshot(p), true
+}
+
+func (g *Game) copyInspected() {
+	r, ok := g.inspected()
+	if !ok {
+		return
+	}
+	if err := inspector.CopyToClipboard(r.PayloadJSON()); err != nil {
+		log.Printf("Advertencia: No se pudo copiar al portapapeles: %v", err)
+		g.inspectorMsg = i18n.T("inspector.copy_failed", err)
+		return
+	}
+	g.inspectorMsg = i18n.T("inspector.copied")
+}
+
+func (g *Game) dumpInspected() {
+	r, ok := g.inspected()
+	if !ok {
+		return
+	}
+	path, err := inspector.WriteFile(".", r, time.Now())
+	if err != nil {
+		log.Printf("Advertencia: No se pudo guardar el paquete: %v", err)
+		g.inspectorMsg = i18n.T("inspector.save_failed", err)
+		return
+	}
+	g.inspectorMsg = i18n.T("inspector.saved", path)
+}
+
+func (g *Game) drawInspector(screen *ebiten.Image) {
+	inspectorX, inspectorY := g.inspectorOrigin()
+	listTop := int(inspectorY + inspectorListTop)
+	vector.FillRect(screen, float32(inspectorX), float32(inspectorY), inspectorW, inspectorH, inspectorColor, false)
+	vector.StrokeRect(screen, float32(inspectorX), float32(inspectorY), inspectorW, inspectorH, 1, chartFrameColor, false)
+	divX := float32(inspectorX + inspectorListW)
+	vector.StrokeLine(screen, divX, float32(inspectorY+24), divX, float32(inspectorY+inspectorH), 1, chartFrameColor, false)
+
+	drawText(screen,
+		i18n.T("inspector.title"),
+		int(inspectorX)+8, int(inspectorY)+6)
+
+	g.State.Mutex.Lock()
+	ids := g.packetIDs()
+	for i, id := range ids {
+		prefix := "  "
+		if id == g.inspectedID {
+			prefix = "> "
+		}
+		drawText(screen,
+			fmt.Sprintf("%s%s  %s", prefix, id, g.State.Packets[id].Status),
+			int(inspectorX)+4, listTop+i*inspectorLineH)
+	}
+	g.State.Mutex.Unlock()
+	if len(ids) == 0 {
+		drawText(screen, i18n.T("inspector.empty"), int(inspectorX)+4, listTop)
+	}
+
+	x := int(inspectorX + inspectorListW + 10)
+	y := listTop
+	maxY := int(inspectorY+inspectorH) - 2*inspectorLineH
+	printLine := func(line string) {
+		if y < maxY {
+			drawTextFace(screen, line, x, y, monoFace, color.White)
+		}
+		y += inspectorLineH
+	}
+
+	r, ok := g.inspected()
+	if !ok {
+		printLine(i18n.T("inspector.hint"))
+	} else {
+		http := i18n.T("inspector.no_response")
+		if r.HTTPStatus != 0 {
+			http = fmt.Sprintf("%d", r.HTTPStatus)
+		}
+		printLine(i18n.T("inspector.summary", r.ID, r.Status, http))
+		printLine(i18n.T("inspector.url", r.URL))
+		if r.ErrorReason != "" {
+			for _, line := range wrap(i18n.T("inspector.reason", r.ErrorReason), inspectorChars, 2) {
+				printLine(line)
+			}
+		}
+		for _, line := range r.HeaderLines() {
+			printLine("  " + wrap(line, inspectorChars-2, 1)[0])
+		}
+		response := i18n.T("inspector.response", r.Response)
+		if r.Truncated {
+			response += i18n.T("inspector.truncated")
+		}
+		for _, line := range wrap(response, inspectorChars, 3) {
+			printLine(line)
+		}
+		y += inspectorLineH / 2
+		printLine(i18n.T("inspector.transitions"))
+		for _, line := range r.Timeline() {
+			printLine("  " + line)
+		}
+		y += inspectorLineH / 2
+		if r.BodySize > 0 {
+			printLine(i18n.T("inspector.body_size", r.BodySize, r.ContentType))
+			if r.Compression != "" {
+				printLine(i18n.T("inspector.compressed", r.Compression, r.SentBytes, 100*float64(r.SentBytes)/float64(r.BodySize)))
+			}
+		} else {
+			printLine(i18n.T("inspector.payload"))
+		}
+		for _, line := range strings.Split(r.PayloadJSON(), "\n") {
+			printLine("  " + line)
+		}
+	}
+
+	if g.inspectorMsg != "" {
+		drawText(screen, g.inspectorMsg, x, int(inspectorY+inspectorH)-inspectorLineH-4)
+	}
+}
+
+// wrap corta s en líneas de hasta width caracteres, como máximo maxLines; si
+// sobra texto la última línea termina en "...".
+func wrap(s string, width, maxLines int) []string {
+	runes := []rune(strings.ReplaceAll(s, "\n", " "))
+	var lines []string
+	for len(runes) > 0 && len(lines) < maxLines {
+		n := min(width, len(runes))
+		lines = append(lines, string(runes[:n]))
+		runes = runes[n:]
+	}
+	if len(runes) > 0 {
+		last := []rune(lines[len(lines)-1])
+		lines[len(lines)-1] = string(last[:len(last)-3]) + "..."
+	}
+	return lines
+}
@@ -0,0 +1,33 @@
+package game
+
+import (
+	"geova-simulation/state"
+	"testing"
+)
+
+func TestPacketAtMatchesDrawnSize(t *testing.T) {
+	g := newFixtureGame()
+	g.State.Packets["mpu"] = &state.PacketState{ID: "mpu", Active: true, X: 100, Y: 100, Readings: 1}
+	g.State.Packets["mpu-lote"] = &state.PacketState{ID: "mpu-lote", Active: true, X: 300, Y: 100, Readings: 10}
+
+	// El lote se dibuja 1.6 veces más grande alrededor del mismo centro:
+	// x de 290.4 a 341.6, y de 90.4 a 141.6
+	for _, tt := range []struct {
+		x, y float64
+		want string
+	}{
+		{101, 101, "mpu"},
+		{131, 131, "mpu"},
+		{133, 116, ""}, // Fuera de un paquete simple
+		{95, 116, ""},
+		{292, 116, "mpu-lote"},
+		{340, 140, "mpu-lote"},
+		{316, 91, "mpu-lote"},
+		{289, 116, ""},
+		{316, 142, ""},
+	} {
+		if got := g.packetAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("packetAt(%v, %v) = %q, want %q", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"geova-simulation/pipeline"
+	"image"
+	"math"
+	"os"
+)
+
+// Tamaño de diseño de la escena. La ventana puede tener cualquier tamaño: la
+// escena se escala de forma uniforme para que este rectángulo quepa entero y
+// el espacio que sobra en el otro eje se reparte según las anclas.
+const (
+	designW = 900
+	designH = 650
+)
+
+// Anchor es el punto de la pantalla desde el que se mide una posición.
+type Anchor string
+
+const (
+	TopLeft     Anchor = "top-left"
+	Top         Anchor = "top"
+	TopRight    Anchor = "top-right"
+	Left        Anchor = "left"
+	Center      Anchor = "center"
+	Right       Anchor = "right"
+	BottomLeft  Anchor = "bottom-left"
+	Bottom      Anchor = "bottom"
+	BottomRight Anchor = "bottom-right"
+)
+
+// anchorFractions ubica cada ancla como fracción del ancho y alto.
+var anchorFractions = map[Anchor][2]float64{
+	TopLeft:     {0, 0},
+	Top:         {0.5, 0},
+	TopRight:    {1, 0},
+	Left:        {0, 0.5},
+	Center:      {0.5, 0.5},
+	Right:       {1, 0.5},
+	BottomLeft:  {0, 1},
+	Bottom:      {0.5, 1},
+	BottomRight: {1, 1},
+}
+
+// Pos es una posición relativa a un ancla; sin ancla se mide desde la
+// esquina superior izquierda.
+type Pos struct {
+	Anchor Anchor  `json:"anchor,omitempty"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+}
+
+// at crea una Pos cuyo resultado en la escena de diseño es (x, y).
+func at(a Anchor, x, y float64) Pos {
+	f := anchorFractions[a]
+	return Pos{Anchor: a, X: x - f[0]*designW, Y: y - f[1]*designH}
+}
+
+// Resolve convierte la posición a coordenadas de una escena de w×h.
+func (p Pos) Resolve(w, h float64) pipeline.Point {
+	f := anchorFractions[p.Anchor]
+	return pipeline.Point{X: f[0]*w + p.X, Y: f[1]*h + p.Y}
+}
+
+// Layout son las posiciones de la escena. Se pueden sobreescribir con un
+// archivo JSON (-layout): solo hace falta incluir las claves que cambian.
+type Layout struct {
+	// Tripode es la posición del único Geova; con varios, la pila de
+	// trípodes se centra en ella con DeviceSpacing entre cada uno.
+	Tripode       Pos     `json:"tripode"`
+	DeviceSpacing float64 `json:"device_spacing"`
+
+	TiltMeter  Pos `json:"tilt_meter"`
+	Dashboard  Pos `json:"dashboard"`
+	TimeSlider Pos `json:"time_slider"`
+	Button     Pos `json:"button"`
+
+	Gauge    Pos `json:"gauge"`
+	Camera   Pos `json:"camera"`
+	Frontend Pos `json:"frontend"`
+	Charts   Pos `json:"charts"`
+
+	PythonAPI    Pos `json:"python_api"`
+	RabbitMQ     Pos `json:"rabbitmq"`
+	WebsocketAPI Pos `json:"websocket_api"`
+	Monitor      Pos `json:"monitor"`
+}
+
+// DefaultLayout reproduce la escena original de 900×650: el hardware a la
+// izquierda, el backend centrado, el dashboard abajo a la izquierda y el
+// panel del frontend y el botón abajo a la derecha.
+func DefaultLayout() Layout {
+	cfg := pipeline.DefaultConfig()
+	center := func(p pipeline.Point) Pos { return at(Center, p.X, p.Y) }
+	return Layout{
+		Tripode:       at(Left, 80, 200),
+		DeviceSpacing: pipeline.DeviceSpacing,
+		TiltMeter:     at(TopLeft, 100, 50),
+		Dashboard:     at(BottomLeft, 50, 450),
+		TimeSlider:    at(TopRight, 640, 50),
+		Button:        at(BottomRight, 780, 590),
+
+		Gauge:    at(BottomRight, 430, 425),
+		Camera:   at(BottomRight, 545, 410),
+		Frontend: at(BottomRight, 690, 390),
+		Charts:   at(TopLeft, 40, 80),
+
+		PythonAPI:    center(cfg.PythonAPI.Pos),
+		RabbitMQ:     center(cfg.RabbitMQ.Pos),
+		WebsocketAPI: center(cfg.WebsocketAPI.Pos),
+		Monitor:      center(cfg.Monitor),
+	}
+}
+
+// LoadLayout aplica el archivo JSON en path sobre el layout por defecto.
+func LoadLayout(path string) (Layout, error) {
+	layout := DefaultLayout()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return layout, err
+	}
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return layout, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := layout.validate(); err != nil {
+		return layout, fmt.Errorf("%s: %w", path, err)
+	}
+	return layout, nil
+}
+
+func (l Layout) validate() error {
+	for _, p := range []Pos{
+		l.Tripode, l.TiltMeter, l.Dashboard, l.TimeSlider, l.Button,
+		l.Gauge, l.Camera, l.Frontend, l.Charts,
+		l.PythonAPI, l.RabbitMQ, l.WebsocketAPI, l.Monitor,
+	} {
+		if _, ok := anchorFractions[p.Anchor]; !ok && p.Anchor != "" {
+			return fmt.Errorf("ancla desconocida '%s'", p.Anchor)
+		}
+	}
+	if l.DeviceSpacing <= 0 {
+		return fmt.Errorf("device_spacing debe ser positivo")
+	}
+	return nil
+}
+
+// scene es el Layout resuelto para el tamaño actual de la escena.
+type scene struct {
+	TiltMeter, Dashboard, TimeSlider, Button pipeline.Point
+	Gauge, Camera, Frontend, Charts, Monitor pipeline.Point
+
+	// Devices es la posición del trípode de cada dispositivo y tripodScale
+	// la escala con que se dibujan para que la pila no se superponga.
+	Devices     []pipeline.Point
+	tripodScale float64
+}
+
+func (l Layout) resolve(w, h float64, devices int) (scene, pipeline.Positions) {
+	points := l.devicePoints(l.Tripode.Resolve(w, h), devices)
+	scale := 1.0
+	if devices > 1 {
+		scale = math.Min(1, l.DeviceSpacing/tripodeSize)
+	}
+	return scene{
+		Devices:     points,
+		tripodScale: scale,
+		TiltMeter:   l.TiltMeter.Resolve(w, h),
+		Dashboard:   l.Dashboard.Resolve(w, h),
+		TimeSlider:  l.TimeSlider.Resolve(w, h),
+		Button:      l.Button.Resolve(w, h),
+		Gauge:       l.Gauge.Resolve(w, h),
+		Camera:      l.Camera.Resolve(w, h),
+		Frontend:    l.Frontend.Resolve(w, h),
+		Charts:      l.Charts.Resolve(w, h),
+		Monitor:     l.Monitor.Resolve(w, h),
+	}, pipeline.Positions{
+		Devices:      points,
+		PythonAPI:    l.PythonAPI.Resolve(w, h),
+		RabbitMQ:     l.RabbitMQ.Resolve(w, h),
+		WebsocketAPI: l.WebsocketAPI.Resolve(w, h),
+		Monitor:      l.Monitor.Resolve(w, h),
+	}
+}
+
+// devicePoints apila n trípodes centrados verticalmente en first.
+func (l Layout) devicePoints(first pipeline.Point, n int) []pipeline.Point {
+	points := make([]pipeline.Point, n)
+	top := first.Y - float64(n-1)*l.DeviceSpacing/2
+	for i := range points {
+		points[i] = pipeline.Point{X: first.X, Y: top + float64(i)*l.DeviceSpacing}
+	}
+	return points
+}
+
+// sceneSize calcula el tamaño lógico de la escena para una ventana de
+// outsideW×outsideH: el lado que limita mide lo mismo que en el diseño y el
+// otro crece, así Ebitengine escala sin deformar.
+func sceneSize(outsideW, outsideH int) (int, int) {
+	if outsideW <= 0 || outsideH <= 0 {
+		return designW, designH
+	}
+	s := math.Min(float64(outsideW)/designW, float64(outsideH)/designH)
+	return int(math.Round(float64(outsideW) / s)), int(math.Round(float64(outsideH) / s))
+}
+
+// SetLayout cambia las posiciones de la escena; los paquetes en vuelo
+// conservan su estado y se redirigen a las nuevas posiciones.
+func (g *Game) SetLayout(l Layout) {
+	g.layout = l
+	g.applyLayout()
+}
+
+// applyLayout resuelve el layout para el tamaño actual de la escena, mueve el
+// área de click del botón y las etapas del pipeline.
+func (g *Game) applyLayout() {
+	sc, positions := g.layout.resolve(float64(g.screenW), float64(g.screenH), len(g.State.Devices))
+	g.scene = sc
+
+	btn := g.Assets.ButtonCreateUp
+	g.BotonRect = image.Rect(int(sc.Button.X), int(sc.Button.Y),
+		int(sc.Button.X)+btn.FrameWidth, int(sc.Button.Y)+btn.FrameHeight)
+
+	g.Pipeline.SetPositions(positions)
+}
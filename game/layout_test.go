@@ -0,0 +1,61 @@
+package game
+
+import (
+	"geova-simulation/pipeline"
+	"testing"
+)
+
+func TestDefaultLayoutMatchesDesign(t *testing.T) {
+	sc, pos := DefaultLayout().resolve(designW, designH, 1)
+	cfg := pipeline.DefaultConfig()
+
+	checks := []struct {
+		name      string
+		got, want pipeline.Point
+	}{
+		{"tripode", sc.Devices[0], pipeline.Point{X: 80, Y: 200}},
+		{"button", sc.Button, pipeline.Point{X: 780, Y: 590}},
+		{"time_slider", sc.TimeSlider, pipeline.Point{X: 640, Y: 50}},
+		{"dashboard", sc.Dashboard, pipeline.Point{X: 50, Y: 450}},
+		{"rabbitmq", pos.RabbitMQ, cfg.RabbitMQ.Pos},
+		{"monitor", pos.Monitor, cfg.Monitor},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestAnchorsFollowWindowSize(t *testing.T) {
+	// Una ventana más ancha mantiene el alto de diseño y agrega ancho
+	w, h := sceneSize(1920, 1080)
+	if h != designH || w != 1156 {
+		t.Fatalf("sceneSize(1920, 1080) = %d×%d, want 1156×%d", w, h, designH)
+	}
+
+	sc, pos := DefaultLayout().resolve(float64(w), float64(h), 1)
+	if want := (pipeline.Point{X: float64(w) - 120, Y: 590}); sc.Button != want {
+		t.Errorf("button = %v, want %v", sc.Button, want)
+	}
+	if sc.Devices[0].X != 80 {
+		t.Errorf("tripode.X = %v, want 80", sc.Devices[0].X)
+	}
+	if want := 250 + float64(w-designW)/2; pos.PythonAPI.X != want {
+		t.Errorf("python_api.X = %v, want %v", pos.PythonAPI.X, want)
+	}
+}
+
+func TestDevicesStackAroundTripode(t *testing.T) {
+	sc, pos := DefaultLayout().resolve(designW, designH, 3)
+
+	want := []pipeline.Point{{X: 80, Y: 90}, {X: 80, Y: 200}, {X: 80, Y: 310}}
+	for i, p := range want {
+		if sc.Devices[i] != p || pos.Devices[i] != p {
+			t.Errorf("dispositivo %d en %v (pipeline %v), want %v", i, sc.Devices[i], pos.Devices[i], p)
+		}
+	}
+	if want := pipeline.DeviceSpacing / tripodeSize; sc.tripodScale != want {
+		t.Errorf("tripodScale = %v, want %v", sc.tripodScale, want)
+	}
+}
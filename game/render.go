@@ -2,12 +2,15 @@ package game
 
 import (
 	"fmt"
+	"geova-simulation/assets"
+	"geova-simulation/i18n"
+	"geova-simulation/pipeline"
 	"geova-simulation/state"
-	"image"
 	"image/color"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 func (g *Game) Draw(screen *ebiten.Image) {
@@ -18,70 +21,150 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	g.drawPackets(screen)
 	g.drawButton(screen)
 	g.drawDashboard(screen)
-	ebitenutil.DebugPrintAt(screen, "Controles:  Flechas <- -> para inclinar ANTES de crear  |  Click en CREAR  |  F11 pantalla completa", 10, 10)
+	g.drawFrontend(screen)
+	g.drawTimeControls(screen)
+	if g.showCharts {
+		g.drawCharts(screen)
+	}
+	if g.showInspector {
+		g.drawInspector(screen)
+	}
+	if g.scenario != nil {
+		g.drawScenario(screen)
+	}
+	drawText(screen, i18n.T("help.controls"), 10, 10)
+	drawText(screen, i18n.T("help.keys"), 10, g.screenH-18)
+}
+
+// drawScenario muestra el avance del escenario y, al terminar, cuántas
+// expectativas se cumplieron; el detalle va al log.
+func (g *Game) drawScenario(screen *ebiten.Image) {
+	r := g.scenario
+	msg := ""
+	if r.Finished() {
+		passed, results := 0, r.Results()
+		for _, res := range results {
+			if res.Passed {
+				passed++
+			}
+		}
+		msg = i18n.T("scenario.finished", r.Scenario.Name, passed, len(results))
+	} else {
+		done, total := r.Progress()
+		msg = i18n.T("scenario.running", r.Scenario.Name, r.Elapsed().Seconds(), done, total)
+	}
+	drawText(screen, msg, 10, 28)
 }
 
 func (g *Game) drawBackground(screen *ebiten.Image) {
 	if g.Assets.Background != nil {
 		op := &ebiten.DrawImageOptions{}
-		screenW, screenH := screen.Bounds().Dx(), screen.Bounds().Dy()
-		bgW, bgH := g.Assets.Background.Bounds().Dx(), g.Assets.Background.Bounds().Dy()
-
-		scaleX := float64(screenW) / float64(bgW)
-		scaleY := float64(screenH) / float64(bgH)
-
-		op.GeoM.Scale(scaleX, scaleY)
-		screen.DrawImage(g.Assets.Background, op)
+		screenW, screenH := float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy())
+		bgW, bgH := float64(g.Assets.Background.FrameWidth), float64(g.Assets.Background.FrameHeight)
+
+		// Escala uniforme que cubre toda la pantalla; lo que sobra se recorta
+		// por igual a ambos lados
+		scale := math.Max(screenW/bgW, screenH/bgH)
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate((screenW-bgW*scale)/2, (screenH-bgH*scale)/2)
+		op.Filter = ebiten.FilterLinear
+		screen.DrawImage(g.Assets.Background.FrameAt(g.animTime), op)
 	} else {
 		screen.Fill(color.RGBA{R: 0x1a, G: 0x1a, B: 0x1a, A: 255})
 	}
 }
 
+// drawTripode dibuja el trípode de cada dispositivo. Con varios, cada uno
+// lleva su nombre a la izquierda y el seleccionado se marca con "►".
 func (g *Game) drawTripode(screen *ebiten.Image) {
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(tripodeX, tripodeY)
+	sprite := g.Assets.UITiltMeter
+	scale := g.scene.tripodScale
+	multi := len(g.State.Devices) > 1
 
-	frameIndex := g.getTripodeFrame(g.State.CurrentTilt)
-	sx := frameIndex * tripodeFrameWidth
-	rect := image.Rect(sx, 0, sx+tripodeFrameWidth, tripodeFrameHeight)
-
-	screen.DrawImage(g.Assets.UITiltMeter.SubImage(rect).(*ebiten.Image), op)
+	for i, d := range g.State.Devices {
+		pos := g.scene.Devices[i]
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(pos.X, pos.Y)
+		screen.DrawImage(sprite.Frame(tripodeFrame(d.Tilt, sprite.FrameCount())), op)
+
+		if multi {
+			label := "  " + d.Name
+			if i == g.State.Selected {
+				label = "► " + d.Name
+			}
+			drawTextColor(screen, label, int(pos.X)-75, int(pos.Y+tripodeSize*scale/2)-8, d.Color)
+		}
+	}
 }
 
-func (g *Game) getTripodeFrame(tilt float64) int {
-	switch {
-	case tilt <= -12.5:
+// tripodeFrame reparte el rango de inclinación ±maxTilt entre los frames del
+// sprite del trípode; el frame central es el trípode nivelado.
+func tripodeFrame(tilt float64, frameCount int) int {
+	if frameCount <= 1 {
 		return 0
-	case tilt <= -7.5:
-		return 1
-	case tilt <= -2.5:
-		return 2
-	case tilt < 2.5:
-		return 3
-	case tilt < 7.5:
-		return 4
-	case tilt < 12.5:
-		return 5
-	default:
-		return 6
 	}
+	pos := (tilt + maxTilt) / (2 * maxTilt)
+	return int(math.Round(pos * float64(frameCount-1)))
 }
 
 func (g *Game) drawTiltMeter(screen *ebiten.Image) {
-	ebitenutil.DebugPrintAt(screen,
-		fmt.Sprintf("Inclinación Actual: %.1f°", g.State.CurrentTilt),
-		int(tiltMeterX), int(tiltMeterY))
+	d := g.State.SelectedDevice()
+	if d == nil {
+		return
+	}
+	drawText(screen,
+		i18n.T("tilt.current", d.Tilt, d.Pitch),
+		int(g.scene.TiltMeter.X), int(g.scene.TiltMeter.Y))
+	if len(g.State.Devices) > 1 {
+		drawTextColor(screen, i18n.T("tilt.device", d.Name),
+			int(g.scene.TiltMeter.X), int(g.scene.TiltMeter.Y)+14, d.Color)
+	}
 
-	meterX := int(tiltMeterX) + 200
-	meterY := int(tiltMeterY)
+	// Nivel de burbuja de dos ejes a la derecha del texto
+	cx := float32(g.scene.TiltMeter.X) + 300
+	cy := float32(g.scene.TiltMeter.Y) + 8
+	ringColor := color.RGBA{R: 180, G: 180, B: 180, A: 255}
+	vector.FillCircle(screen, cx, cy, levelRadius, color.RGBA{R: 30, G: 60, B: 40, A: 255}, true)
+	vector.StrokeCircle(screen, cx, cy, levelRadius, 1.5, ringColor, true)
+	vector.StrokeCircle(screen, cx, cy, levelBubbleRad+2, 1, ringColor, true)
+	vector.StrokeLine(screen, cx-levelRadius, cy, cx+levelRadius, cy, 1, ringColor, false)
+	vector.StrokeLine(screen, cx, cy-levelRadius, cx, cy+levelRadius, 1, ringColor, false)
+
+	bx, by := bubbleOffset(d.Tilt, d.Pitch, levelRadius-levelBubbleRad)
+	bubbleColor := color.RGBA{R: 255, G: 200, B: 50, A: 255}
+	if math.Abs(d.Tilt) <= levelTolerance && math.Abs(d.Pitch) <= levelTolerance {
+		bubbleColor = color.RGBA{R: 120, G: 255, B: 120, A: 255}
+	}
+	vector.FillCircle(screen, cx+float32(bx), cy+float32(by), levelBubbleRad, bubbleColor, true)
+}
 
-	for i := -15; i <= 15; i++ {
-		x := meterX + i*3
-		ebitenutil.DebugPrintAt(screen, "|", x, meterY)
+// bubbleOffset ubica la burbuja dentro de un círculo de radio r. Como en un
+// nivel real, la burbuja sube hacia el lado más alto: con roll positivo (el
+// trípode cae a la derecha) se va a la izquierda y con pitch positivo (frente
+// levantado) hacia arriba.
+func bubbleOffset(roll, pitch, r float64) (float64, float64) {
+	x, y := -roll/maxTilt*r, -pitch/maxTilt*r
+	if d := math.Hypot(x, y); d > r {
+		x, y = x/d*r, y/d*r
 	}
+	return x, y
+}
+
+func (g *Game) drawTimeControls(screen *ebiten.Image) {
+	trackColor := color.RGBA{R: 120, G: 120, B: 120, A: 255}
+	knobColor := color.RGBA{R: 255, G: 200, B: 50, A: 255}
+
+	sliderX, sliderY := float32(g.scene.TimeSlider.X), float32(g.scene.TimeSlider.Y)
+	vector.StrokeLine(screen, sliderX, sliderY, sliderX+sliderWidth, sliderY, 2, trackColor, true)
+	knobX := sliderX + float32(sliderFromTimeScale(g.timeScale))*sliderWidth
+	vector.FillCircle(screen, knobX, sliderY, sliderKnobRad, knobColor, true)
 
-	markerX := meterX + int(g.State.CurrentTilt*3)
-	ebitenutil.DebugPrintAt(screen, "▼", markerX-2, meterY-15)
+	status := i18n.T("time.speed", g.timeScale)
+	if g.paused {
+		status += i18n.T("time.paused")
+	}
+	drawText(screen, status, int(sliderX), int(sliderY)+10)
 }
 
 func (g *Game) drawButton(screen *ebiten.Image) {
@@ -90,54 +173,95 @@ func (g *Game) drawButton(screen *ebiten.Image) {
 
 	if g.State.SimulacionIniciada {
 		op.ColorScale.Scale(0.5, 0.5, 0.5, 1.0)
-		screen.DrawImage(g.Assets.ButtonCreateUp, op)
+		screen.DrawImage(g.Assets.ButtonCreateUp.FrameAt(g.animTime), op)
 	} else if g.isBotonPressed {
-		screen.DrawImage(g.Assets.ButtonCreateDown, op)
+		screen.DrawImage(g.Assets.ButtonCreateDown.FrameAt(g.animTime), op)
 	} else {
-		screen.DrawImage(g.Assets.ButtonCreateUp, op)
+		screen.DrawImage(g.Assets.ButtonCreateUp.FrameAt(g.animTime), op)
 	}
 }
 
 func (g *Game) drawIcons(screen *ebiten.Image) {
-	g.drawIcon(screen, g.Assets.IconPythonIdle, g.Assets.IconPythonActiveAnim,
-		g.State.PythonAPITimer, iconPythonX, iconPythonY)
-	g.drawIcon(screen, g.Assets.IconRabbitIdle, g.Assets.IconRabbitActiveAnim,
-		g.State.RabbitMQTimer, iconRabbitX, iconRabbitY)
-	g.drawIcon(screen, g.Assets.IconWebsocketIdle, g.Assets.IconWebsocketActiveAnim,
-		g.State.WebsocketAPITimer, iconWebsocketX, iconWebsocketY)
+	g.State.Mutex.Lock()
+	defer g.State.Mutex.Unlock()
+
+	g.drawIcon(screen, g.Assets.IconPythonIdle, g.Assets.IconPythonActiveAnim, g.State.PythonAPI)
+	g.drawIcon(screen, g.Assets.IconRabbitIdle, g.Assets.IconRabbitActiveAnim, g.State.RabbitMQ)
+	g.drawIcon(screen, g.Assets.IconWebsocketIdle, g.Assets.IconWebsocketActiveAnim, g.State.WebsocketAPI)
+
+	// Tamaño del cuerpo de las peticiones que recibe la API, para comparar
+	// formatos
+	if avg, ok := averageBodySize(g.State.Packets); ok {
+		stage := g.State.PythonAPI
+		drawText(screen, i18n.T("stage.payload", avg), int(stage.X)-10, int(stage.Y)+94)
+	}
+	if encoding, ratio, ok := wireRatio(g.State.Packets); ok {
+		stage := g.State.PythonAPI
+		drawText(screen, i18n.T("stage.wire", encoding, 100*ratio), int(stage.X)-10, int(stage.Y)+108)
+	}
 
 	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(monitorX, monitorY)
-	screen.DrawImage(g.Assets.IconMonitor, op)
+	op.GeoM.Translate(g.scene.Monitor.X, g.scene.Monitor.Y)
+	screen.DrawImage(g.Assets.IconMonitor.FrameAt(g.animTime), op)
+}
+
+// averageBodySize es el tamaño medio en bytes del cuerpo de los paquetes que
+// ya se serializaron.
+func averageBodySize(packets map[string]*state.PacketState) (float64, bool) {
+	total, n := 0, 0
+	for _, packet := range packets {
+		if len(packet.Body) > 0 {
+			total += len(packet.Body)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return float64(total) / float64(n), true
 }
 
-func (g *Game) drawIcon(screen *ebiten.Image, idle *ebiten.Image, anim *ebiten.Image,
-	timer int, x, y float64) {
+// wireRatio es la proporción entre los bytes que salieron comprimidos y el
+// tamaño original de esos cuerpos, con la compresión que se usó.
+func wireRatio(packets map[string]*state.PacketState) (encoding string, ratio float64, ok bool) {
+	body, sent := 0, 0
+	for _, packet := range packets {
+		if packet.ContentEncoding != "" && packet.SentBytes > 0 {
+			encoding = packet.ContentEncoding
+			body += len(packet.Body)
+			sent += packet.SentBytes
+		}
+	}
+	if body == 0 {
+		return "", 0, false
+	}
+	return encoding, float64(sent) / float64(body), true
+}
+
+func (g *Game) drawIcon(screen *ebiten.Image, idle *assets.Sprite, anim *assets.Sprite,
+	stage *state.StageState) {
 	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(x, y)
-
-	if timer > 0 {
-		frameWidth := 64
-		frameCount := 6
-		frameIndex := (g.animIconCounter / 6) % frameCount
-		sx := frameIndex * frameWidth
-		rect := image.Rect(sx, 0, sx+frameWidth, 64)
-		screen.DrawImage(anim.SubImage(rect).(*ebiten.Image), op)
+	op.GeoM.Translate(stage.X, stage.Y)
+
+	if stage.Busy() {
+		screen.DrawImage(anim.FrameAt(g.animTime), op)
 	} else {
-		screen.DrawImage(idle, op)
+		screen.DrawImage(idle.FrameAt(g.animTime), op)
 	}
+
+	drawText(screen,
+		i18n.T("stage.slots", len(stage.InService), stage.Capacity, len(stage.Queue)),
+		int(stage.X)-10, int(stage.Y)+66)
+	drawText(screen,
+		i18n.T("stage.usage", stage.Utilisation()*100),
+		int(stage.X)-10, int(stage.Y)+80)
 }
 
 func (g *Game) drawPackets(screen *ebiten.Image) {
 	g.State.Mutex.Lock()
 	defer g.State.Mutex.Unlock()
 
-	frameWidth := 32
-	frameCount := 6
-	frameIndex := (g.animPacketCounter / 6) % frameCount
-	sx := frameIndex * frameWidth
-	rect := image.Rect(sx, 0, sx+frameWidth, 32)
-	packetFrame := g.Assets.DataPacketAnim.SubImage(rect).(*ebiten.Image)
+	packetFrame := g.Assets.DataPacketAnim.FrameAt(g.animTime)
 
 	for _, packet := range g.State.Packets {
 		if !packet.Active {
@@ -145,6 +269,12 @@ func (g *Game) drawPackets(screen *ebiten.Image) {
 		}
 
 		op := &ebiten.DrawImageOptions{}
+		if scale := packetScale(packet); scale != 1 {
+			// Un lote se dibuja más grande, centrado en el mismo punto
+			w, h := packetFrame.Bounds().Dx(), packetFrame.Bounds().Dy()
+			op.GeoM.Scale(scale, scale)
+			op.GeoM.Translate(-float64(w)*(scale-1)/2, -float64(h)*(scale-1)/2)
+		}
 		op.GeoM.Translate(packet.X, packet.Y)
 
 		c := packet.Color.(color.RGBA)
@@ -154,52 +284,96 @@ func (g *Game) drawPackets(screen *ebiten.Image) {
 
 		screen.DrawImage(packetFrame, op)
 
-		labelX := int(packet.X) - 15
-		labelY := int(packet.Y) - 10
-
-		var label string
-		switch packet.ID {
-		case "tfluna":
-			label = "TFL"
-		case "mpu":
-			label = "MPU"
-		case "imx":
-			label = "IMX"
+		label, labelColor := g.packetLabel(packet)
+		drawTextColor(screen, label, int(packet.X)-15, int(packet.Y)-10, labelColor)
+		// Tamaño del cuerpo, para comparar formatos paquete a paquete
+		if n := len(packet.Body); n > 0 {
+			drawText(screen, i18n.T("packet.size", n), int(packet.X)-15, int(packet.Y)-24)
 		}
 
-		ebitenutil.DebugPrintAt(screen, label, labelX, labelY)
-
 		if packet.Status == state.Error {
-			ebitenutil.DebugPrintAt(screen, "✗ ERROR", int(packet.X)-10, int(packet.Y)+25)
+			msg := i18n.T("packet.error")
+			if packet.ErrorReason != "" {
+				msg = i18n.T("packet.error_at", wrap(packet.ErrorReason, errorReasonChars, 1)[0])
+			}
+			drawText(screen, msg, int(packet.X)-10, int(packet.Y)+25)
 		}
 	}
 }
 
-func (g *Game) drawDashboard(screen *ebiten.Image) {
-	y := int(dashboardY)
+// batchScale agranda los paquetes que llevan un lote de lecturas.
+const batchScale = 1.6
+
+// packetScale es la escala con que se dibuja packet.
+func packetScale(packet *state.PacketState) float64 {
+	if packet.Readings > 1 {
+		return batchScale
+	}
+	return 1
+}
 
-	ebitenutil.DebugPrintAt(screen, "--- Dashboard de Resultados ---", int(dashboardX), y)
+var sensorLabels = map[pipeline.Sensor]string{
+	pipeline.TFLuna: "TFL",
+	pipeline.MPU:    "MPU",
+	pipeline.IMX:    "IMX",
+}
+
+// packetLabel es el sensor del paquete, con la cantidad de lecturas si es un
+// lote ("MPU×10") y, con varios dispositivos, el número del que lo envió
+// ("2·MPU") en el color de ese dispositivo.
+func (g *Game) packetLabel(packet *state.PacketState) (string, color.Color) {
+	label := sensorLabels[pipeline.SensorOf(packet.ID)]
+	if packet.Readings > 1 {
+		label += fmt.Sprintf("×%d", packet.Readings)
+	}
+	if len(g.State.Devices) < 2 {
+		return label, color.White
+	}
+	for i, d := range g.State.Devices {
+		if d.ID == packet.Device {
+			return fmt.Sprintf("%d·%s", i+1, label), d.Color
+		}
+	}
+	return label, color.White
+}
+
+func (g *Game) drawDashboard(screen *ebiten.Image) {
+	dashboardX := g.scene.Dashboard.X
+	y := int(g.scene.Dashboard.Y)
+	multi := len(g.State.Devices) > 1
+
+	// El detalle es del dispositivo seleccionado; con varios, debajo va una
+	// línea de resumen por cada uno.
+	d := g.State.SelectedDevice()
+	if d == nil {
+		return
+	}
+	if multi {
+		drawTextColor(screen, i18n.T("dashboard.device_title", d.Name), int(dashboardX), y, d.Color)
+	} else {
+		drawText(screen, i18n.T("dashboard.title"), int(dashboardX), y)
+	}
 	y += 20
 
-	distText := fmt.Sprintf("  Distancia (TFLuna): %.2f m", g.State.DisplayDistancia)
-	if g.State.DisplayDistancia == 0 {
-		distText = "  Distancia (TFLuna): --"
+	distText := i18n.T("dashboard.distance", d.DisplayDistancia)
+	if d.DisplayDistancia == 0 {
+		distText = i18n.T("dashboard.distance_na")
 	}
-	ebitenutil.DebugPrintAt(screen, distText, int(dashboardX), y)
+	drawText(screen, distText, int(dashboardX), y)
 	y += 25
 
-	nitText := "  Nitidez (IMX477):"
-	if g.State.DisplayNitidez == 0 {
-		nitText = "  Nitidez (IMX477): --"
+	nitText := i18n.T("dashboard.sharpness")
+	if d.DisplayNitidez == 0 {
+		nitText = i18n.T("dashboard.sharpness_na")
 	}
-	ebitenutil.DebugPrintAt(screen, nitText, int(dashboardX), y)
+	drawText(screen, nitText, int(dashboardX), y)
 
-	if g.State.DisplayNitidez > 0 {
+	if d.DisplayNitidez > 0 {
 		opBarBG := &ebiten.DrawImageOptions{}
 		opBarBG.GeoM.Translate(dashboardX+180, float64(y))
-		screen.DrawImage(g.Assets.UIProgressBG, opBarBG)
+		screen.DrawImage(g.Assets.UIProgressBG.FrameAt(g.animTime), opBarBG)
 
-		normalizedNitidez := (g.State.DisplayNitidez - 4.0) / 2.0
+		normalizedNitidez := (d.DisplayNitidez - 4.0) / 2.0
 		if normalizedNitidez < 0 {
 			normalizedNitidez = 0
 		}
@@ -210,26 +384,51 @@ func (g *Game) drawDashboard(screen *ebiten.Image) {
 		opBarFill := &ebiten.DrawImageOptions{}
 		opBarFill.GeoM.Scale(normalizedNitidez, 1.0)
 		opBarFill.GeoM.Translate(dashboardX+180, float64(y))
-		screen.DrawImage(g.Assets.UIProgressFill, opBarFill)
+		screen.DrawImage(g.Assets.UIProgressFill.FrameAt(g.animTime), opBarFill)
 
-		ebitenutil.DebugPrintAt(screen,
-			fmt.Sprintf("%.2f", g.State.DisplayNitidez),
+		drawText(screen,
+			fmt.Sprintf("%.2f", d.DisplayNitidez),
 			int(dashboardX)+330, y)
 	}
 
 	y += 25
 
-	rollText := fmt.Sprintf("  Inclinacion Roll (MPU): %.1f°", g.State.DisplayRoll)
-	if g.State.DisplayRoll == 0 {
-		rollText = "  Inclinacion Roll (MPU): --"
+	rollText := i18n.T("dashboard.roll", d.DisplayRoll)
+	if d.DisplayRoll == 0 {
+		rollText = i18n.T("dashboard.roll_na")
 	}
-	ebitenutil.DebugPrintAt(screen, rollText, int(dashboardX), y)
+	drawText(screen, rollText, int(dashboardX), y)
 
-	y += 30
+	if multi {
+		y += 20
+		for _, dev := range g.State.Devices {
+			drawTextColor(screen, deviceSummary(dev), int(dashboardX), y, dev.Color)
+			y += 15
+		}
+		y += 10
+	} else {
+		y += 30
+	}
 
-	if g.State.SimulacionIniciada {
-		ebitenutil.DebugPrintAt(screen, ">> Procesando solicitudes...", int(dashboardX), y)
+	if g.State.Streaming {
+		drawText(screen, i18n.T("dashboard.streaming"), int(dashboardX), y)
+	} else if g.State.SimulacionIniciada {
+		drawText(screen, i18n.T("dashboard.running"), int(dashboardX), y)
 	} else {
-		ebitenutil.DebugPrintAt(screen, ">> Listo para nueva simulacion", int(dashboardX), y)
+		drawText(screen, i18n.T("dashboard.ready"), int(dashboardX), y)
+	}
+}
+
+// deviceSummary resume en una línea lo último que llegó de un dispositivo.
+func deviceSummary(d *state.DeviceState) string {
+	value := func(v float64, format string) string {
+		if v == 0 {
+			return "--"
+		}
+		return fmt.Sprintf(format, v)
 	}
+	return i18n.T("dashboard.summary", d.Name,
+		value(d.DisplayDistancia, "%.2f m"),
+		value(d.DisplayNitidez, "%.2f"),
+		value(d.DisplayRoll, "%.1f°"))
 }
@@ -0,0 +1,240 @@
+package game
+
+import (
+	"flag"
+	"fmt"
+	"geova-simulation/assets"
+	"geova-simulation/clock"
+	"geova-simulation/pipeline"
+	"geova-simulation/simulation"
+	"geova-simulation/state"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Regenerar los goldens:  go test ./game -run TestDrawGolden -update
+var update = flag.Bool("update", false, "reescribe los PNG golden de testdata/golden")
+
+const (
+	goldenDir = "testdata/golden"
+
+	// Diferencia máxima por canal que se considera ruido del driver gráfico
+	// y fracción máxima de píxeles que pueden superarla.
+	channelTolerance = 8
+	maxDiffRatio     = 0.002
+)
+
+var testAssets *assets.Assets
+
+// testRunner ejecuta la suite dentro del game loop de Ebitengine: solo ahí se
+// puede dibujar y leer píxeles de las imágenes.
+type testRunner struct {
+	m    *testing.M
+	code int
+}
+
+func (r *testRunner) Update() error {
+	r.code = r.m.Run()
+	return ebiten.Termination
+}
+
+func (r *testRunner) Draw(screen *ebiten.Image) {}
+
+func (r *testRunner) Layout(w, h int) (int, int) { return 1, 1 }
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	var err error
+	if testAssets, err = assets.LoadAssets(os.DirFS("../images")); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	ebiten.SetWindowSize(1, 1)
+	runner := &testRunner{m: m}
+	if err := ebiten.RunGameWithOptions(runner, &ebiten.RunGameOptions{InitUnfocused: true}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(runner.code)
+}
+
+var goldenEpoch = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newFixtureGame() *Game {
+	visState := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+	pipe := pipeline.New(visState, clock.NewFake(goldenEpoch), pipeline.DefaultConfig())
+	return NewGame(testAssets, pipe)
+}
+
+func TestDrawGolden(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(g *Game)
+	}{
+		{
+			name:  "idle",
+			setup: func(g *Game) {},
+		},
+		{
+			name: "packets_in_flight",
+			setup: func(g *Game) {
+				s := g.State
+				s.SimulacionIniciada = true
+				s.Devices[0].Tilt = 5
+				s.Packets["tfluna"] = &state.PacketState{
+					ID: "tfluna", Active: true, X: 160, Y: 190,
+					Color: color.RGBA{R: 255, G: 50, B: 50, A: 255}, Status: state.SendingToAPI,
+				}
+				mpu := &state.PacketState{
+					ID: "mpu", Active: true, X: 400, Y: 200,
+					Color: color.RGBA{R: 50, G: 150, B: 255, A: 255}, Status: state.ProcessingAtRabbit,
+				}
+				s.Packets["mpu"] = mpu
+				s.RabbitMQ.InService = []*state.PacketState{mpu}
+				s.RabbitMQ.BusyTime = 300 * time.Millisecond
+				s.RabbitMQ.TotalTime = time.Second
+				imx := &state.PacketState{
+					ID: "imx", Active: true, X: 266, Y: 300,
+					Color: color.RGBA{R: 50, G: 255, B: 50, A: 255}, Status: state.QueuedAtAPI,
+				}
+				s.Packets["imx"] = imx
+				s.PythonAPI.InService = []*state.PacketState{{ID: "otro"}}
+				s.PythonAPI.Queue = []*state.PacketState{imx}
+			},
+		},
+		{
+			name: "error",
+			setup: func(g *Game) {
+				g.State.Packets["tfluna"] = &state.PacketState{
+					ID: "tfluna", Active: true, X: 250, Y: 200,
+					Color: color.RGBA{R: 255, G: 50, B: 50, A: 255}, Status: state.Error,
+				}
+			},
+		},
+		{
+			name: "dashboard_populated",
+			setup: func(g *Game) {
+				s := g.State
+				d := s.Devices[0]
+				d.Tilt = -7.5
+				d.DisplayDistancia = 2.34
+				d.DisplayNitidez = 5.2
+				d.DisplayRoll = -7.5
+				d.DisplayPitch = 1.2
+				d.DisplayLaser = true
+				s.Packets["mpu"] = &state.PacketState{
+					ID: "mpu", Status: state.Done, Payload: simulation.MPUData{Roll: -7.5},
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newFixtureGame()
+			tt.setup(g)
+
+			w, h := g.Layout(0, 0)
+			screen := ebiten.NewImage(w, h)
+			defer screen.Deallocate()
+			g.Draw(screen)
+
+			got := readImage(screen)
+			path := filepath.Join(goldenDir, tt.name+".png")
+
+			if *update {
+				if err := writePNG(path, got); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := readPNG(path)
+			if os.IsNotExist(err) {
+				t.Fatalf("no existe %s; generarlo con -update", path)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff, ratio := compareImages(got, want); ratio > maxDiffRatio {
+				actual := filepath.Join(t.TempDir(), tt.name+".png")
+				writePNG(actual, got)
+				t.Errorf("%s difiere del golden: %d píxeles (%.3f%%); resultado en %s",
+					tt.name, diff, ratio*100, actual)
+			}
+		})
+	}
+}
+
+func readImage(img *ebiten.Image) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(b)
+	img.ReadPixels(out.Pix)
+	return out
+}
+
+// compareImages cuenta los píxeles que difieren más de channelTolerance en
+// algún canal y devuelve ese número y su proporción sobre el total.
+func compareImages(got, want *image.RGBA) (int, float64) {
+	if got.Bounds() != want.Bounds() {
+		n := got.Bounds().Dx() * got.Bounds().Dy()
+		return n, 1
+	}
+	diff := 0
+	for i := 0; i < len(got.Pix); i += 4 {
+		for c := 0; c < 4; c++ {
+			d := int(got.Pix[i+c]) - int(want.Pix[i+c])
+			if d > channelTolerance || d < -channelTolerance {
+				diff++
+				break
+			}
+		}
+	}
+	return diff, float64(diff) / float64(len(got.Pix)/4)
+}
+
+func readPNG(path string) (*image.RGBA, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba, nil
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			rgba.Set(x, y, img.At(x, y))
+		}
+	}
+	return rgba, nil
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
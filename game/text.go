@@ -0,0 +1,48 @@
+package game
+
+import (
+	"bytes"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+const fontSize = 12
+
+// Las fuentes Go van embebidas en el binario y cubren los acentos y símbolos
+// de la interfaz (°, ▼, ×). monoFace se usa donde importa alinear columnas,
+// como el JSON del inspector.
+var (
+	uiFace   = loadFace(goregular.TTF)
+	monoFace = loadFace(gomono.TTF)
+)
+
+func loadFace(ttf []byte) text.Face {
+	src, err := text.NewGoTextFaceSource(bytes.NewReader(ttf))
+	if err != nil {
+		log.Fatalf("Error: no se pudo cargar la fuente: %v", err)
+	}
+	return &text.GoTextFace{Source: src, Size: fontSize}
+}
+
+// drawText escribe s en blanco con (x, y) como esquina superior izquierda,
+// igual que ebitenutil.DebugPrintAt.
+func drawText(screen *ebiten.Image, s string, x, y int) {
+	drawTextFace(screen, s, x, y, uiFace, color.White)
+}
+
+func drawTextColor(screen *ebiten.Image, s string, x, y int, clr color.Color) {
+	drawTextFace(screen, s, x, y, uiFace, clr)
+}
+
+func drawTextFace(screen *ebiten.Image, s string, x, y int, face text.Face, clr color.Color) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	op.ColorScale.ScaleWithColor(clr)
+	op.LineSpacing = fontSize * 1.3
+	text.Draw(screen, s, face, op)
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	keyTiltStep   = 0.5  // Grados por frame con las flechas
+	dragTiltScale = 0.1  // Grados por píxel al arrastrar el trípode
+	stickTiltRate = 20.0 // Grados por segundo con el stick a fondo
+	stickDeadZone = 0.15
+	tripodeSize   = 128.0
+)
+
+// handleTilt ajusta roll (horizontal) y pitch (vertical) del dispositivo
+// seleccionado con las flechas, arrastrando su trípode con el mouse o con el
+// stick izquierdo del gamepad. Tab o un click en otro trípode cambian la
+// selección.
+func (g *Game) handleTilt(x, y int) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) && len(g.State.Devices) > 0 {
+		g.State.Selected = (g.State.Selected + 1) % len(g.State.Devices)
+	}
+
+	roll, pitch := 0.0, 0.0
+
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
+		roll -= keyTiltStep
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyRight) {
+		roll += keyTiltStep
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyUp) {
+		pitch += keyTiltStep
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyDown) {
+		pitch -= keyTiltStep
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		fx, fy := float64(x), float64(y)
+		g.draggingTripod = false
+		if i := g.tripodAt(fx, fy); i >= 0 && !g.chartsContain(fx, fy) {
+			g.State.Selected = i
+			g.draggingTripod = true
+		}
+		g.dragX, g.dragY = x, y
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		g.draggingTripod = false
+	}
+	if g.draggingTripod {
+		// Arrastrar hacia arriba levanta el frente (pitch positivo)
+		roll += float64(x-g.dragX) * dragTiltScale
+		pitch -= float64(y-g.dragY) * dragTiltScale
+		g.dragX, g.dragY = x, y
+	}
+
+	dt := 1.0 / float64(ebiten.TPS())
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		h, v := stickAxes(id)
+		roll += deadZone(h) * stickTiltRate * dt
+		pitch -= deadZone(v) * stickTiltRate * dt
+	}
+
+	// El streaming lee la inclinación desde su goroutine
+	g.State.Mutex.Lock()
+	defer g.State.Mutex.Unlock()
+	if d := g.State.SelectedDevice(); d != nil {
+		d.Tilt = clampTilt(d.Tilt + roll)
+		d.Pitch = clampTilt(d.Pitch + pitch)
+	}
+}
+
+// tripodAt retorna el índice del dispositivo cuyo trípode está bajo (x, y),
+// o -1.
+func (g *Game) tripodAt(x, y float64) int {
+	size := tripodeSize * g.scene.tripodScale
+	for i, p := range g.scene.Devices {
+		if x >= p.X && x < p.X+size && y >= p.Y && y < p.Y+size {
+			return i
+		}
+	}
+	return -1
+}
+
+// stickAxes lee el stick izquierdo; usa el layout estándar si ebiten lo
+// reconoce y si no los dos primeros ejes crudos.
+func stickAxes(id ebiten.GamepadID) (float64, float64) {
+	if ebiten.IsStandardGamepadLayoutAvailable(id) {
+		return ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal),
+			ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+	}
+	if ebiten.GamepadAxisCount(id) < 2 {
+		return 0, 0
+	}
+	return ebiten.GamepadAxisValue(id, 0), ebiten.GamepadAxisValue(id, 1)
+}
+
+// deadZone anula el ruido del stick en reposo y reescala el resto a [-1, 1].
+func deadZone(v float64) float64 {
+	if math.Abs(v) < stickDeadZone {
+		return 0
+	}
+	return math.Copysign((math.Abs(v)-stickDeadZone)/(1-stickDeadZone), v)
+}
+
+func clampTilt(v float64) float64 {
+	return math.Max(-maxTilt, math.Min(maxTilt, v))
+}
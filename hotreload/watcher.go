@@ -0,0 +1,123 @@
+// Package hotreload vigila archivos en disco durante el desarrollo. Usa
+// polling de mtime/tamaño para no depender de APIs de notificación del SO.
+package hotreload
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher revisa cada Interval los archivos de Dirs (sin recursión) y los
+// Files sueltos. Los cambios se acumulan hasta que alguien llama a Changes.
+type Watcher struct {
+	Dirs     []string
+	Files    []string
+	Interval time.Duration
+
+	mu      sync.Mutex
+	stamps  map[string]fileStamp
+	changed map[string]bool
+	stop    chan struct{}
+}
+
+func New(interval time.Duration, dirs, files []string) *Watcher {
+	return &Watcher{
+		Dirs:     dirs,
+		Files:    files,
+		Interval: interval,
+		changed:  make(map[string]bool),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start toma una primera foto de los archivos y lanza la goroutine de polling.
+func (w *Watcher) Start() {
+	w.stamps = w.scan()
+	go func() {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.poll()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (w *Watcher) Stop() {
+	close(w.stop)
+}
+
+// Changes devuelve las rutas modificadas, creadas o borradas desde la última
+// llamada, o nil si no hubo cambios.
+func (w *Watcher) Changes() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.changed) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(w.changed))
+	for p := range w.changed {
+		paths = append(paths, p)
+	}
+	w.changed = make(map[string]bool)
+	return paths
+}
+
+func (w *Watcher) poll() {
+	current := w.scan()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for path, stamp := range current {
+		if prev, ok := w.stamps[path]; !ok || prev != stamp {
+			w.changed[path] = true
+		}
+	}
+	for path := range w.stamps {
+		if _, ok := current[path]; !ok {
+			w.changed[path] = true
+		}
+	}
+	w.stamps = current
+}
+
+func (w *Watcher) scan() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	add := func(path string, info fs.FileInfo) {
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	for _, dir := range w.Dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			if info, err := e.Info(); err == nil {
+				add(filepath.Join(dir, e.Name()), info)
+			}
+		}
+	}
+	for _, file := range w.Files {
+		if info, err := os.Stat(file); err == nil {
+			add(file, info)
+		}
+	}
+	return stamps
+}
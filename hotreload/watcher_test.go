@@ -0,0 +1,57 @@
+package hotreload
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// waitChanges acumula los cambios reportados hasta ver n rutas distintas.
+func waitChanges(t *testing.T, w *Watcher, n int) []string {
+	t.Helper()
+	seen := make(map[string]bool)
+	deadline := time.Now().Add(2 * time.Second)
+	for len(seen) < n && time.Now().Before(deadline) {
+		for _, p := range w.Changes() {
+			seen[p] = true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	got := make([]string, 0, len(seen))
+	for p := range seen {
+		got = append(got, p)
+	}
+	sort.Strings(got)
+	return got
+}
+
+func TestWatcherReportsChanges(t *testing.T) {
+	dir := t.TempDir()
+	sprite := filepath.Join(dir, "monitor.png")
+	config := filepath.Join(t.TempDir(), "layout.json")
+	os.WriteFile(sprite, []byte("v1"), 0o644)
+
+	w := New(10*time.Millisecond, []string{dir}, []string{config})
+	w.Start()
+	defer w.Stop()
+
+	if changes := w.Changes(); changes != nil {
+		t.Fatalf("cambios antes de modificar nada: %v", changes)
+	}
+
+	os.WriteFile(sprite, []byte("version 2"), 0o644)
+	os.WriteFile(config, []byte("{}"), 0o644)
+	got := waitChanges(t, w, 2)
+	want := []string{sprite, config}
+	sort.Strings(want)
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("cambios = %v, want %v", got, want)
+	}
+
+	os.Remove(sprite)
+	if got := waitChanges(t, w, 1); len(got) != 1 || got[0] != sprite {
+		t.Errorf("cambios tras borrar = %v, want [%s]", got, sprite)
+	}
+}
@@ -0,0 +1,85 @@
+package i18n
+
+var en = map[string]string{
+	// Help
+	"help.controls": "Controls:  Arrows, drag the tripod or gamepad to tilt  |  Tab switches Geova  |  Click CREATE  |  F11 fullscreen",
+	"help.keys":     "Space pause/resume  |  N step one frame while paused  |  Drag the slider for speed  |  S streaming  |  G charts  |  I inspector",
+
+	// Scene
+	"tilt.current":    "Tilt  Roll: %.1f°  Pitch: %.1f°",
+	"tilt.device":     "Controlling: %s (Tab to switch)",
+	"time.speed":      "Speed: %.2fx",
+	"time.paused":     "  [PAUSED]",
+	"stage.slots":     "Slots %d/%d  Queue %d",
+	"stage.payload":   "Avg. payload %.0f B",
+	"stage.wire":      "%s: %.0f%% of original",
+	"stage.usage":     "Usage %3.0f%%",
+	"packet.error":    "× ERROR",
+	"packet.error_at": "× ERROR: %s",
+	"packet.size":     "%d B",
+
+	// Packet error reasons
+	"error.refused":  "connection refused",
+	"error.timeout":  "timed out",
+	"error.network":  "network error",
+	"error.encode":   "could not serialize as %s",
+	"error.injected": "injected fault: %d %s",
+
+	// Scenarios
+	"scenario.running":  "Scenario %s  t=%.1fs  step %d/%d",
+	"scenario.finished": "Scenario %s finished: %d/%d expectations met",
+
+	// Dashboard
+	"dashboard.title":        "--- Results Dashboard ---",
+	"dashboard.device_title": "--- Dashboard: %s ---",
+	"dashboard.summary":      "  %s:  %s  ·  Sharp. %s  ·  Roll %s",
+	"dashboard.distance":     "  Distance (TFLuna): %.2f m",
+	"dashboard.distance_na":  "  Distance (TFLuna): --",
+	"dashboard.sharpness":    "  Sharpness (IMX477):",
+	"dashboard.sharpness_na": "  Sharpness (IMX477): --",
+	"dashboard.roll":         "  Roll tilt (MPU): %.1f°",
+	"dashboard.roll_na":      "  Roll tilt (MPU): --",
+	"dashboard.streaming":    ">> Streaming MPU (S to stop)",
+	"dashboard.running":      ">> Processing requests...",
+	"dashboard.ready":        ">> Ready for a new simulation",
+
+	// Frontend panel
+	"frontend.roll":      "Roll %.1f°",
+	"frontend.laser_na":  "IMX477  laser: --",
+	"frontend.laser_yes": "IMX477  laser: YES",
+	"frontend.laser_no":  "IMX477  laser: NO",
+	"frontend.distance":  "%.2f m",
+	"frontend.angles":    "R %.1f° P %.1f°",
+	"frontend.sharpness": "Sharp %.2f",
+	"frontend.dot_yes":   "Laser YES",
+	"frontend.dot_no":    "Laser NO",
+
+	// Charts
+	"chart.no_data":      "no data",
+	"chart.stats":        "min %.2f  max %.2f  avg %.2f",
+	"metric.distance":    "Distance (m)",
+	"metric.roll":        "Roll (°)",
+	"metric.pitch":       "Pitch (°)",
+	"metric.sharpness":   "Sharpness",
+	"metric.signal":      "Signal strength",
+	"metric.temperature": "Temperature (°C)",
+
+	// Inspector
+	"inspector.title":       "Packet inspector   [C] copy JSON   [D] save to file   [I/Esc] close",
+	"inspector.empty":       "  (no packets)",
+	"inspector.hint":        "Click a packet or a list entry to inspect it.",
+	"inspector.no_response": "no response",
+	"inspector.summary":     "Packet: %s   Status: %s   HTTP: %s",
+	"inspector.url":         "URL: %s",
+	"inspector.reason":      "Reason: %s",
+	"inspector.response":    "Response: %s",
+	"inspector.truncated":   " (truncated)",
+	"inspector.transitions": "Transitions:",
+	"inspector.payload":     "Payload:",
+	"inspector.body_size":   "Payload: %d B sent as %s",
+	"inspector.compressed":  "  %s: %d B on the wire (%.0f%%)",
+	"inspector.copied":      "JSON copied to clipboard",
+	"inspector.copy_failed": "Could not copy: %v",
+	"inspector.saved":       "Saved to %s",
+	"inspector.save_failed": "Could not save: %v",
+}
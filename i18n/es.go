@@ -0,0 +1,85 @@
+package i18n
+
+var es = map[string]string{
+	// Ayuda
+	"help.controls": "Controles:  Flechas, arrastrar el trípode o gamepad para inclinar  |  Tab cambia de Geova  |  Click en CREAR  |  F11 pantalla completa",
+	"help.keys":     "Espacio pausa/reanuda  |  N avanza un frame en pausa  |  Arrastra el slider para la velocidad  |  S streaming  |  G gráficas  |  I inspector",
+
+	// Escena
+	"tilt.current":    "Inclinación  Roll: %.1f°  Pitch: %.1f°",
+	"tilt.device":     "Controlando: %s (Tab cambia)",
+	"time.speed":      "Velocidad: %.2fx",
+	"time.paused":     "  [PAUSA]",
+	"stage.slots":     "Slots %d/%d  Cola %d",
+	"stage.usage":     "Uso %3.0f%%",
+	"stage.payload":   "Payload prom. %.0f B",
+	"stage.wire":      "%s: %.0f%% del original",
+	"packet.error":    "× ERROR",
+	"packet.error_at": "× ERROR: %s",
+	"packet.size":     "%d B",
+
+	// Motivos de error de los paquetes
+	"error.refused":  "conexión rechazada",
+	"error.timeout":  "tiempo de espera agotado",
+	"error.network":  "error de red",
+	"error.encode":   "no se pudo serializar como %s",
+	"error.injected": "falla inyectada: %d %s",
+
+	// Escenarios
+	"scenario.running":  "Escenario %s  t=%.1fs  paso %d/%d",
+	"scenario.finished": "Escenario %s terminado: %d/%d expectativas cumplidas",
+
+	// Dashboard
+	"dashboard.title":        "--- Dashboard de Resultados ---",
+	"dashboard.device_title": "--- Dashboard: %s ---",
+	"dashboard.summary":      "  %s:  %s  ·  Nit. %s  ·  Roll %s",
+	"dashboard.distance":     "  Distancia (TFLuna): %.2f m",
+	"dashboard.distance_na":  "  Distancia (TFLuna): --",
+	"dashboard.sharpness":    "  Nitidez (IMX477):",
+	"dashboard.sharpness_na": "  Nitidez (IMX477): --",
+	"dashboard.roll":         "  Inclinación Roll (MPU): %.1f°",
+	"dashboard.roll_na":      "  Inclinación Roll (MPU): --",
+	"dashboard.streaming":    ">> Streaming del MPU (S para detener)",
+	"dashboard.running":      ">> Procesando solicitudes...",
+	"dashboard.ready":        ">> Listo para nueva simulación",
+
+	// Panel del frontend
+	"frontend.roll":      "Roll %.1f°",
+	"frontend.laser_na":  "IMX477  láser: --",
+	"frontend.laser_yes": "IMX477  láser: SÍ",
+	"frontend.laser_no":  "IMX477  láser: NO",
+	"frontend.distance":  "%.2f m",
+	"frontend.angles":    "R %.1f° P %.1f°",
+	"frontend.sharpness": "Nit %.2f",
+	"frontend.dot_yes":   "Láser SÍ",
+	"frontend.dot_no":    "Láser NO",
+
+	// Gráficas
+	"chart.no_data":      "sin datos",
+	"chart.stats":        "mín %.2f  máx %.2f  prom %.2f",
+	"metric.distance":    "Distancia (m)",
+	"metric.roll":        "Roll (°)",
+	"metric.pitch":       "Pitch (°)",
+	"metric.sharpness":   "Nitidez",
+	"metric.signal":      "Fuerza señal",
+	"metric.temperature": "Temperatura (°C)",
+
+	// Inspector
+	"inspector.title":       "Inspector de paquetes   [C] copiar JSON   [D] guardar archivo   [I/Esc] cerrar",
+	"inspector.empty":       "  (sin paquetes)",
+	"inspector.hint":        "Haz click en un paquete o en la lista para inspeccionarlo.",
+	"inspector.no_response": "sin respuesta",
+	"inspector.summary":     "Paquete: %s   Estado: %s   HTTP: %s",
+	"inspector.url":         "URL: %s",
+	"inspector.reason":      "Motivo: %s",
+	"inspector.response":    "Respuesta: %s",
+	"inspector.truncated":   " (cortada)",
+	"inspector.transitions": "Transiciones:",
+	"inspector.payload":     "Payload:",
+	"inspector.body_size":   "Payload: %d B enviados como %s",
+	"inspector.compressed":  "  %s: %d B en la red (%.0f%%)",
+	"inspector.copied":      "JSON copiado al portapapeles",
+	"inspector.copy_failed": "No se pudo copiar: %v",
+	"inspector.saved":       "Guardado en %s",
+	"inspector.save_failed": "No se pudo guardar: %v",
+}
@@ -0,0 +1,70 @@
+// Package i18n guarda los textos que ve el usuario en cada idioma. El idioma
+// se elige al arrancar (-lang) y los textos se piden por clave con T.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+)
+
+type Lang string
+
+const (
+	Spanish Lang = "es"
+	English Lang = "en"
+
+	// Default es el idioma original de la simulación; sus textos son el
+	// respaldo cuando a otro catálogo le falta una clave.
+	Default = Spanish
+)
+
+var catalogs = map[Lang]map[string]string{
+	Spanish: es,
+	English: en,
+}
+
+var current atomic.Value // Lang
+
+func init() {
+	current.Store(Default)
+}
+
+// SetLang cambia el idioma de la interfaz.
+func SetLang(l Lang) error {
+	if _, ok := catalogs[l]; !ok {
+		return fmt.Errorf("idioma '%s' no disponible (opciones: %v)", l, Available())
+	}
+	current.Store(l)
+	return nil
+}
+
+func Current() Lang {
+	return current.Load().(Lang)
+}
+
+// Available lista los idiomas con catálogo, ordenados.
+func Available() []Lang {
+	langs := make([]Lang, 0, len(catalogs))
+	for l := range catalogs {
+		langs = append(langs, l)
+	}
+	sort.Slice(langs, func(i, j int) bool { return langs[i] < langs[j] })
+	return langs
+}
+
+// T retorna el texto de key en el idioma actual, formateado con args como
+// fmt.Sprintf. Si falta en el idioma actual usa el de Default, y si tampoco
+// existe retorna la clave para que se note en pantalla.
+func T(key string, args ...interface{}) string {
+	msg, ok := catalogs[Current()][key]
+	if !ok {
+		if msg, ok = catalogs[Default][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
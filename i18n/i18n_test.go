@@ -0,0 +1,59 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+)
+
+var verb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// TestCatalogsMatch comprueba que todos los idiomas tengan las mismas claves y
+// los mismos verbos de formato, en el mismo orden.
+func TestCatalogsMatch(t *testing.T) {
+	base := catalogs[Default]
+	for lang, cat := range catalogs {
+		for key, msg := range base {
+			other, ok := cat[key]
+			if !ok {
+				t.Errorf("%s: falta la clave %q", lang, key)
+				continue
+			}
+			want, got := verb.FindAllString(msg, -1), verb.FindAllString(other, -1)
+			if len(want) != len(got) {
+				t.Errorf("%s: %q tiene verbos %v, want %v", lang, key, got, want)
+				continue
+			}
+			for i := range want {
+				if want[i][len(want[i])-1] != got[i][len(got[i])-1] {
+					t.Errorf("%s: %q tiene verbos %v, want %v", lang, key, got, want)
+					break
+				}
+			}
+		}
+		for key := range cat {
+			if _, ok := base[key]; !ok {
+				t.Errorf("%s: clave %q no existe en %s", lang, key, Default)
+			}
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	t.Cleanup(func() { SetLang(Default) })
+
+	if got := T("stage.usage", 42.0); got != "Uso  42%" {
+		t.Errorf("es: %q", got)
+	}
+	if err := SetLang(English); err != nil {
+		t.Fatal(err)
+	}
+	if got := T("stage.usage", 42.0); got != "Usage  42%" {
+		t.Errorf("en: %q", got)
+	}
+	if got := T("no.existe"); got != "no.existe" {
+		t.Errorf("clave faltante: %q", got)
+	}
+	if err := SetLang("fr"); err == nil {
+		t.Error("SetLang(fr) no retornó error")
+	}
+}
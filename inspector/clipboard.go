@@ -0,0 +1,44 @@
+package inspector
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands son las herramientas del sistema que se prueban, en orden,
+// para copiar texto al portapapeles.
+var clipboardCommands = map[string][][]string{
+	"windows": {{"clip"}},
+	"darwin":  {{"pbcopy"}},
+	"linux": {
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	},
+}
+
+var errNoClipboard = errors.New("no se encontró una herramienta de portapapeles (clip, pbcopy, wl-copy, xclip o xsel)")
+
+// clipboardCommand elige la primera herramienta disponible para goos.
+func clipboardCommand(goos string, lookPath func(string) (string, error)) ([]string, error) {
+	for _, cmd := range clipboardCommands[goos] {
+		if _, err := lookPath(cmd[0]); err == nil {
+			return cmd, nil
+		}
+	}
+	return nil, errNoClipboard
+}
+
+// CopyToClipboard pasa text por stdin a la herramienta de portapapeles del
+// sistema operativo.
+func CopyToClipboard(text string) error {
+	args, err := clipboardCommand(runtime.GOOS, exec.LookPath)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
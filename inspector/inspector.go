@@ -0,0 +1,124 @@
+// Package inspector arma la vista detallada de un paquete (payload, petición
+// HTTP y línea de tiempo) y la exporta al portapapeles o a un archivo. No
+// depende de Ebitengine; la UI solo dibuja el Record.
+package inspector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"geova-simulation/state"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type TransitionRecord struct {
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// Record es una copia de un paquete que se puede leer sin tomar el mutex del
+// estado.
+type Record struct {
+	ID          string             `json:"id"`
+	Status      string             `json:"status"`
+	URL         string             `json:"url"`
+	ContentType string             `json:"content_type,omitempty"`
+	BodySize    int                `json:"body_size"`
+	Compression string             `json:"content_encoding,omitempty"`
+	SentBytes   int                `json:"sent_bytes,omitempty"`
+	HTTPStatus  int                `json:"http_status,omitempty"`
+	Headers     map[string]string  `json:"headers,omitempty"`
+	Response    string             `json:"response,omitempty"`
+	Truncated   bool               `json:"response_truncated,omitempty"`
+	ErrorReason string             `json:"error_reason,omitempty"`
+	Transitions []TransitionRecord `json:"transitions"`
+	Payload     json.RawMessage    `json:"payload"`
+}
+
+// Snapshot copia los datos del paquete. El llamador debe tener tomado el
+// mutex del VisualState.
+func Snapshot(p *state.PacketState) Record {
+	r := Record{
+		ID:          p.ID,
+		Status:      p.Status.String(),
+		URL:         p.URL,
+		ContentType: p.ContentType,
+		BodySize:    len(p.Body),
+		Compression: p.ContentEncoding,
+		SentBytes:   p.SentBytes,
+		HTTPStatus:  p.HTTPStatus,
+		Response:    p.Response,
+		Truncated:   p.ResponseTruncated,
+		ErrorReason: p.ErrorReason,
+	}
+	if len(p.ResponseHeader) > 0 {
+		r.Headers = make(map[string]string, len(p.ResponseHeader))
+		for k, v := range p.ResponseHeader {
+			r.Headers[k] = strings.Join(v, ", ")
+		}
+	}
+	for _, t := range p.Transitions {
+		r.Transitions = append(r.Transitions, TransitionRecord{Status: t.Status.String(), At: t.At})
+	}
+	if json.Valid(p.Body) {
+		r.Payload = json.RawMessage(p.Body)
+	} else if data, err := json.Marshal(p.Payload); err == nil {
+		r.Payload = data
+	} else {
+		r.Payload = json.RawMessage("null")
+	}
+	return r
+}
+
+// PayloadJSON es el payload formateado con sangría, tal como se envió.
+func (r Record) PayloadJSON() string {
+	var b bytes.Buffer
+	if err := json.Indent(&b, r.Payload, "", "  "); err != nil {
+		return string(r.Payload)
+	}
+	return b.String()
+}
+
+// HeaderLines lista los headers de la respuesta ordenados por nombre.
+func (r Record) HeaderLines() []string {
+	lines := make([]string, 0, len(r.Headers))
+	for k, v := range r.Headers {
+		lines = append(lines, k+": "+v)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// Timeline describe cada transición con su tiempo relativo a la primera.
+func (r Record) Timeline() []string {
+	lines := make([]string, 0, len(r.Transitions))
+	for _, t := range r.Transitions {
+		offset := t.At.Sub(r.Transitions[0].At)
+		lines = append(lines, fmt.Sprintf("%s  +%6.3fs  %s",
+			t.At.Format("15:04:05.000"), offset.Seconds(), t.Status))
+	}
+	return lines
+}
+
+var idReplacer = strings.NewReplacer("/", "_", `\`, "_")
+
+// WriteFile guarda el Record completo como JSON en dir y retorna la ruta.
+func WriteFile(dir string, r Record, now time.Time) (string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	// Los IDs llevan el dispositivo ("geova1/mpu"); la barra no puede ir en el
+	// nombre del archivo
+	id := idReplacer.Replace(r.ID)
+	name := fmt.Sprintf("paquete_%s_%s.json", id, now.Format("20060102_150405"))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
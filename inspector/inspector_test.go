@@ -0,0 +1,103 @@
+package inspector
+
+import (
+	"encoding/json"
+	"errors"
+	"geova-simulation/simulation"
+	"geova-simulation/state"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func testPacket() *state.PacketState {
+	p := &state.PacketState{
+		ID:         "tfluna",
+		URL:        "http://localhost:8000/tfluna/sensor",
+		HTTPStatus: 201,
+		Response:   `{"id":7}`,
+		Payload:    simulation.TFLunaData{IDProject: 4, DistanciaCm: 175, DistanciaM: 1.75},
+	}
+	p.SetStatus(state.SendingToAPI, epoch)
+	p.SetStatus(state.ArrivedAtAPI, epoch.Add(750*time.Millisecond))
+	return p
+}
+
+func TestSnapshot(t *testing.T) {
+	r := Snapshot(testPacket())
+
+	if r.Status != "ArrivedAtAPI" || r.HTTPStatus != 201 || r.URL == "" {
+		t.Errorf("Record = %+v", r)
+	}
+	if !strings.Contains(r.PayloadJSON(), "\n  \"distancia_m\": 1.75") {
+		t.Errorf("PayloadJSON sin sangría o sin campos:\n%s", r.PayloadJSON())
+	}
+
+	timeline := r.Timeline()
+	if len(timeline) != 2 || !strings.Contains(timeline[1], "+ 0.750s  ArrivedAtAPI") {
+		t.Errorf("Timeline = %q", timeline)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path, err := WriteFile(dir, Snapshot(testPacket()), epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(path, "paquete_tfluna_20250101_120000.json") {
+		t.Errorf("ruta = %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Record
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	var payload simulation.TFLunaData
+	json.Unmarshal(got.Payload, &payload)
+	if got.ID != "tfluna" || len(got.Transitions) != 2 || payload.DistanciaCm != 175 {
+		t.Errorf("archivo = %s", data)
+	}
+}
+
+func TestWriteFileDeviceID(t *testing.T) {
+	dir := t.TempDir()
+	packet := testPacket()
+	packet.ID = "geova2/mpu-batch3"
+	path, err := WriteFile(dir, Snapshot(packet), epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "paquete_geova2_mpu-batch3_20250101_120000.json"); path != want {
+		t.Errorf("ruta = %s, want %s", path, want)
+	}
+}
+
+func TestClipboardCommand(t *testing.T) {
+	only := func(name string) func(string) (string, error) {
+		return func(file string) (string, error) {
+			if file == name {
+				return "/usr/bin/" + file, nil
+			}
+			return "", errors.New("no encontrado")
+		}
+	}
+
+	if cmd, err := clipboardCommand("linux", only("xclip")); err != nil || cmd[0] != "xclip" {
+		t.Errorf("linux con xclip = %v, %v", cmd, err)
+	}
+	if cmd, err := clipboardCommand("windows", only("clip")); err != nil || cmd[0] != "clip" {
+		t.Errorf("windows = %v, %v", cmd, err)
+	}
+	if _, err := clipboardCommand("linux", only("nada")); err != errNoClipboard {
+		t.Errorf("sin herramientas: err = %v", err)
+	}
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"embed"
+	"flag"
 	"geova-simulation/assets"
+	"geova-simulation/clock"
+	"geova-simulation/codec"
 	"geova-simulation/game"
+	"geova-simulation/hotreload"
+	"geova-simulation/i18n"
+	"geova-simulation/pipeline"
+	"geova-simulation/scenario"
+	"geova-simulation/simulation"
 	"geova-simulation/state"
-	"image"
+	"io/fs"
 	"log"
 	"math/rand"
 	"time"
@@ -12,6 +21,9 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+//go:embed images/*.png images/manifest.json
+var embeddedImages embed.FS
+
 // --- Constantes Globales ---
 const (
 	windowWidth  = 900
@@ -19,41 +31,143 @@ const (
 )
 
 func main() {
+	skinDir := flag.String("skin", "", "directorio de skin: PNG y manifest.json que reemplazan a los embebidos")
+	layoutPath := flag.String("layout", "", "archivo JSON con las coordenadas de la escena")
+	stagesPath := flag.String("stages", "", "archivo JSON con la capacidad y el tiempo de servicio de cada etapa")
+	devMode := flag.Bool("dev", false, "modo desarrollo: recarga assets y layout al guardarlos")
+	devices := flag.Int("devices", 1, "cantidad de Geova en la escena")
+	devicesPath := flag.String("device-config", "", "archivo JSON con el proyecto y los sensores de cada Geova")
+	scenarioPath := flag.String("scenario", "", "archivo de escenario a ejecutar (ver scenarios/)")
+	lang := flag.String("lang", string(i18n.Default), "idioma de la interfaz (es, en)")
+	schema := flag.String("schema", string(simulation.SchemaV1), "versión de los payloads (v1, v2) o archivo .json con un mapeo de campos")
+	encoding := flag.String("encoding", string(codec.JSON), "formato del cuerpo de las peticiones (json, msgpack, cbor, protobuf)")
+	compress := flag.String("compress", "none", "compresión del cuerpo de las peticiones (none, gzip, zstd)")
+	authPath := flag.String("auth", "", "archivo JSON con las credenciales de cada endpoint (sin él se leen las variables GEOVA_AUTH_*)")
+	batchSize := flag.Int("batch", 0, "en streaming, agrupa hasta N lecturas de cada sensor en un solo POST")
+	batchWindow := flag.Duration("batch-window", 0, "en streaming, envía el lote cuando pasa este tiempo desde su primera lectura")
+	batchFormat := flag.String("batch-format", string(simulation.BatchJSON), "formato de los lotes (json, ndjson)")
+	batchURL := flag.String("batch-url", "", "endpoint de los lotes; {sensor} se reemplaza por el sensor (por defecto <URL del sensor>/batch)")
+	flag.Parse()
+
+	if err := i18n.SetLang(i18n.Lang(*lang)); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	// Workers y FSM comparten el mismo reloj
+	clk := clock.Real{}
+	cfg := pipeline.DefaultConfig()
+	var err error
+	if cfg.Schema, err = simulation.ParseSchema(*schema); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if cfg.Encoding, err = codec.Parse(*encoding); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if cfg.Compression, err = simulation.ParseContentEncoding(*compress); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if cfg.Auth, err = simulation.LoadAuth(*authPath, clk); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	for _, rule := range cfg.Auth {
+		log.Printf("🔐 Auth %s", rule)
+	}
+
 	// 1. Inicializa el generador de números aleatorios (¡Importante!)
 	// (En Go 1.20+ esto ya no es necesario, pero no hace daño)
 	rand.New(rand.NewSource(time.Now().UnixNano()))
 
 	// 2. Cargar todos los Assets
-	// Llama a la función LoadAssets que definimos en el paquete 'assets'
-	gameAssets := assets.LoadAssets()
+	// Los PNG van embebidos en el binario; -skin permite reemplazarlos
+	imagesFS, err := fs.Sub(embeddedImages, "images")
+	if err != nil {
+		log.Fatal(err)
+	}
+	// En modo desarrollo se leen los PNG del disco (images/ o el skin) para
+	// poder recargarlos; lo que falte sale de los embebidos
+	assetDir := *skinDir
+	if *devMode && assetDir == "" {
+		assetDir = "images"
+	}
+	if assetDir != "" {
+		if imagesFS, err = assets.WithOverride(assetDir, imagesFS); err != nil {
+			log.Fatalf("Error: skin inválido: %v", err)
+		}
+	}
+	gameAssets, err := assets.LoadAssets(imagesFS)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
 	log.Println("✅ Todos los assets cargados.")
 
 	// 3. Crear el Estado Compartido
 	// Este es el objeto que las goroutines (workers) y la UI (game)
 	// usarán para comunicarse.
+	// Los dispositivos los crea el pipeline a partir de su configuración
 	visualState := &state.VisualState{
-		Packets:      make(map[string]*state.PacketState),
-		CurrentTilt:  0.0, // Inclinación inicial
+		Packets: make(map[string]*state.PacketState),
 	}
 
 	// 4. Crear la Instancia del Juego
-	// Define la "zona de clic" para el botón de crear
-	// (Ajusta estos números para mover tu botón)
-	btnX0 := float64(windowWidth - 120) // Esquina derecha
-	btnY0 := float64(windowHeight - 60) // Abajo
-	btnRect := image.Rect(int(btnX0), int(btnY0), int(btnX0+100), int(btnY0+40)) // (100x40 de tamaño)
+	// La zona de click del botón CREAR sale del layout (ancla abajo a la derecha).
+	if cfg.Devices, err = pipeline.LoadDevices(*devices, *devicesPath); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if *stagesPath != "" {
+		if cfg, err = pipeline.LoadStages(*stagesPath, cfg); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+	cfg.Batch.Size, cfg.Batch.Window, cfg.Batch.URL = *batchSize, *batchWindow, *batchURL
+	if cfg.Batch.Format, err = simulation.ParseBatchFormat(*batchFormat); err == nil {
+		err = cfg.Batch.Check(cfg.Encoding)
+	}
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	pipe := pipeline.New(visualState, clk, cfg)
+	juego := game.NewGame(gameAssets, pipe)
 
-	juego := game.NewGame(gameAssets, visualState, btnRect)
+	if *scenarioPath != "" {
+		sc, err := scenario.Load(*scenarioPath)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		runner, err := scenario.NewRunner(sc, pipe)
+		if err != nil {
+			log.Fatalf("Error: %s: %v", *scenarioPath, err)
+		}
+		juego.RunScenario(runner)
+	}
+
+	if *layoutPath != "" {
+		layout, err := game.LoadLayout(*layoutPath)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		juego.SetLayout(layout)
+	}
+	if *devMode {
+		var files []string
+		if *layoutPath != "" {
+			files = append(files, *layoutPath)
+		}
+		watcher := hotreload.New(500*time.Millisecond, []string{assetDir}, files)
+		watcher.Start()
+		juego.EnableDevReload(watcher, imagesFS, *layoutPath)
+		log.Printf("♻️  Modo desarrollo: vigilando %s %v", assetDir, files)
+	}
 
 	// 5. Configurar y Correr Ebitengine
 	ebiten.SetWindowSize(windowWidth, windowHeight)
 	ebiten.SetWindowTitle("Simulación de Flujo Geova (Concurrente)")
-	
+	// La escena se adapta al tamaño de la ventana (ver game.Layout)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+
 	log.Println("🚀 Iniciando simulación...")
-	
+
 	// ebiten.RunGame toma control del hilo principal
 	// y empezará a llamar a juego.Update() y juego.Draw()
 	if err := ebiten.RunGame(juego); err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
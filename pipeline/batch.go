@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"fmt"
+	"geova-simulation/simulation"
+	"sync"
+	"time"
+)
+
+// batcher junta las lecturas del streaming por dispositivo y sensor hasta
+// que un lote se llena o vence su ventana. Lo usan la goroutine del
+// streaming y StopStreaming, que envía lo que quedó pendiente.
+type batcher struct {
+	cfg BatchConfig
+
+	mu      sync.Mutex
+	pending map[batchKey]*pendingBatch
+	sent    int  // Lotes enviados, para numerar los paquetes
+	closed  bool // Después de drain las lecturas salen de a una
+}
+
+type batchKey struct {
+	device int
+	sensor Sensor
+}
+
+type pendingBatch struct {
+	key     batchKey
+	n       int // Número de lote
+	items   []interface{}
+	started time.Time
+}
+
+func newBatcher(cfg BatchConfig) *batcher {
+	return &batcher{cfg: cfg, pending: make(map[batchKey]*pendingBatch)}
+}
+
+// add suma una lectura y retorna el lote si con ella se llenó.
+func (b *batcher) add(device int, sensor Sensor, payload interface{}, now time.Time) *pendingBatch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := batchKey{device, sensor}
+	batch := b.pending[key]
+	if batch == nil {
+		b.sent++
+		batch = &pendingBatch{key: key, n: b.sent, started: now}
+		if !b.closed {
+			b.pending[key] = batch
+		}
+	}
+	batch.items = append(batch.items, payload)
+	if b.closed || (b.cfg.Size > 0 && len(batch.items) >= b.cfg.Size) {
+		delete(b.pending, key)
+		return batch
+	}
+	return nil
+}
+
+// due retira los lotes cuya ventana venció.
+func (b *batcher) due(now time.Time) []*pendingBatch {
+	if b.cfg.Window <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var due []*pendingBatch
+	for key, batch := range b.pending {
+		if now.Sub(batch.started) >= b.cfg.Window {
+			due = append(due, batch)
+			delete(b.pending, key)
+		}
+	}
+	return due
+}
+
+// drain retira todos los lotes pendientes, aunque no estén llenos.
+func (b *batcher) drain() []*pendingBatch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	batches := make([]*pendingBatch, 0, len(b.pending))
+	for _, batch := range b.pending {
+		batches = append(batches, batch)
+	}
+	b.pending = make(map[batchKey]*pendingBatch)
+	return batches
+}
+
+// sendBatch envía el lote como un solo paquete al endpoint de lotes.
+func (p *Pipeline) sendBatch(batch *pendingBatch) {
+	dev, sensor := p.Config.Devices[batch.key.device], batch.key.sensor
+	payload := simulation.Batch{Format: p.Config.Batch.Format, Items: batch.items}
+	id := fmt.Sprintf("%s/%s-batch%d", dev.ID, sensor, batch.n)
+	go p.Sender.SendPOSTRequest(p.Config.BatchURL(sensor), payload, id, p.State, origin(dev, sensor))
+}
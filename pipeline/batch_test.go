@@ -0,0 +1,110 @@
+package pipeline
+
+import (
+	"bufio"
+	"geova-simulation/simulation"
+	"geova-simulation/state"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBatcher(t *testing.T) {
+	b := newBatcher(BatchConfig{Size: 3, Window: 2 * time.Second})
+
+	for i := 0; i < 2; i++ {
+		if full := b.add(0, MPU, i, epoch); full != nil {
+			t.Fatalf("lectura %d cerró un lote de %d", i, len(full.items))
+		}
+	}
+	b.add(1, MPU, 0, epoch) // Otro dispositivo, otro lote
+	if full := b.add(0, MPU, 2, epoch.Add(time.Second)); full == nil || len(full.items) != 3 || full.n != 1 {
+		t.Fatalf("lote lleno = %+v, want 3 lecturas del lote 1", full)
+	}
+
+	if due := b.due(epoch.Add(time.Second)); len(due) != 0 {
+		t.Errorf("due antes de la ventana = %d lotes", len(due))
+	}
+	if due := b.due(epoch.Add(2 * time.Second)); len(due) != 1 || due[0].key.device != 1 {
+		t.Errorf("due = %+v, want el lote del dispositivo 1", due)
+	}
+
+	b.add(0, IMX, 0, epoch)
+	if rest := b.drain(); len(rest) != 1 || len(rest[0].items) != 1 {
+		t.Errorf("drain = %+v, want el lote del IMX", rest)
+	}
+	// Después de drain las lecturas ya no esperan
+	if full := b.add(0, IMX, 1, epoch); full == nil || len(full.items) != 1 {
+		t.Errorf("add tras drain = %+v, want un lote de 1", full)
+	}
+}
+
+func TestSendBatchNDJSON(t *testing.T) {
+	type request struct {
+		path, contentType string
+		lines             int
+	}
+	got := make(chan request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{path: r.URL.Path, contentType: r.Header.Get("Content-Type")}
+		for s := bufio.NewScanner(r.Body); s.Scan(); {
+			req.lines++
+		}
+		got <- req
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	p, _ := newTestPipeline()
+	p.Config.MPUURL = srv.URL + "/mpu/sensor"
+	p.Config.Batch = BatchConfig{Size: 3, Format: simulation.BatchNDJSON}
+	b := newBatcher(p.Config.Batch)
+	var batch *pendingBatch
+	for _, roll := range []float64{1, 2, 3} {
+		batch = b.add(0, MPU, simulation.MPUData{Roll: roll}, epoch)
+	}
+	p.sendBatch(batch)
+
+	select {
+	case req := <-got:
+		if req.path != "/mpu/sensor/batch" || req.contentType != "application/x-ndjson" || req.lines != 3 {
+			t.Errorf("petición = %+v, want /mpu/sensor/batch, application/x-ndjson y 3 líneas", req)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("el lote no llegó al servidor")
+	}
+
+	p.State.Mutex.Lock()
+	defer p.State.Mutex.Unlock()
+	packet := p.State.Packets["geova1/mpu-batch1"]
+	if packet == nil || packet.Readings != 3 {
+		t.Fatalf("paquete = %+v, want geova1/mpu-batch1 con 3 lecturas", packet)
+	}
+}
+
+func TestBatchURL(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.BatchURL(IMX); got != "http://localhost:8000/imx477/sensor/batch" {
+		t.Errorf("BatchURL por defecto = %s", got)
+	}
+	cfg.Batch.URL = "http://api/v2/{sensor}/bulk"
+	if got := cfg.BatchURL(TFLuna); got != "http://api/v2/tfluna/bulk" {
+		t.Errorf("BatchURL = %s", got)
+	}
+}
+
+// TestDashboardShowsEveryReadingOfBatch comprueba que un lote en Done pasa
+// todas sus lecturas al historial, no solo la última.
+func TestDashboardShowsEveryReadingOfBatch(t *testing.T) {
+	p, _ := newTestPipeline()
+	packet := &state.PacketState{Device: "geova1", Payload: simulation.Batch{Items: []interface{}{
+		simulation.MPUData{Roll: 1}, simulation.MPUData{Roll: 2}, simulation.MPUData{Roll: 5},
+	}}}
+	p.updateDashboard(packet)
+
+	d := p.State.Devices[0]
+	if values := d.History[state.MetricRoll].Values(); len(values) != 3 || d.DisplayRoll != 5 {
+		t.Errorf("historial = %v, roll = %v; want 3 muestras y roll 5", values, d.DisplayRoll)
+	}
+}
@@ -0,0 +1,159 @@
+package pipeline
+
+import (
+	"fmt"
+	"geova-simulation/codec"
+	"geova-simulation/simulation"
+	"geova-simulation/state"
+	"strings"
+	"time"
+)
+
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type StageConfig struct {
+	Name     string
+	Pos      Point
+	Capacity int
+	Service  state.ServiceTime
+}
+
+// Config reúne la geometría y los tiempos de la simulación. Las posiciones
+// están en píxeles de la escena de 900x650 y las velocidades en px/s.
+type Config struct {
+	PacketSpeed float64
+
+	PythonAPI    StageConfig
+	RabbitMQ     StageConfig
+	WebsocketAPI StageConfig
+	Monitor      Point
+
+	QueueOffsetY     float64
+	QueueSlotSpacing float64
+
+	Devices []DeviceConfig
+
+	TFLunaURL string
+	MPUURL    string
+	IMXURL    string
+
+	// Cuerpo de los POST: versión y formato de los payloads, compresión y
+	// credenciales por endpoint. New los pasa al Sender del pipeline.
+	Schema      simulation.SchemaVersion
+	Encoding    codec.Format
+	Compression simulation.ContentEncoding
+	Auth        []simulation.AuthRule
+
+	Stream StreamConfig
+	Batch  BatchConfig
+}
+
+// StreamConfig controla el modo streaming: cada Interval se envía una
+// muestra del MPU con la inclinación del momento, vista a través de un
+// sensor con retardo Lag y ruido Noise (desviación en grados).
+type StreamConfig struct {
+	Interval time.Duration
+	Lag      time.Duration
+	Noise    float64
+}
+
+// BatchConfig agrupa las lecturas del streaming: cada dispositivo junta las
+// de cada sensor y las envía en un solo POST al llegar a Size lecturas o
+// cuando pasa Window desde la primera. Sin Size ni Window cada lectura va
+// en su propia petición.
+type BatchConfig struct {
+	Size   int
+	Window time.Duration
+	Format simulation.BatchFormat
+	// URL del endpoint de lotes; "{sensor}" se reemplaza por el nombre del
+	// sensor. Vacía: la URL del sensor seguida de "/batch".
+	URL string
+}
+
+// Enabled indica si las lecturas se agrupan en lotes.
+func (b BatchConfig) Enabled() bool {
+	return b.Size > 1 || b.Window > 0
+}
+
+// Check valida la configuración de lotes. Los lotes son JSON (arreglo o
+// NDJSON), así que no se combinan con otro encoding del cuerpo.
+func (b BatchConfig) Check(encoding codec.Format) error {
+	if b.Size < 0 || b.Window < 0 {
+		return fmt.Errorf("el tamaño y la ventana de los lotes no pueden ser negativos")
+	}
+	if b.Enabled() && encoding != codec.JSON {
+		return fmt.Errorf("los lotes se envían como JSON; no se pueden combinar con -encoding %s", encoding)
+	}
+	return nil
+}
+
+// BatchURL retorna el endpoint que recibe los lotes de s.
+func (c Config) BatchURL(s Sensor) string {
+	if c.Batch.URL == "" {
+		return c.URL(s) + "/batch"
+	}
+	return strings.ReplaceAll(c.Batch.URL, "{sensor}", string(s))
+}
+
+// URL retorna el endpoint de la API que recibe las lecturas de s.
+func (c Config) URL(s Sensor) string {
+	switch s {
+	case TFLuna:
+		return c.TFLunaURL
+	case MPU:
+		return c.MPUURL
+	case IMX:
+		return c.IMXURL
+	}
+	return ""
+}
+
+const processingDelay = 500 * time.Millisecond
+
+func DefaultConfig() Config {
+	return Config{
+		PacketSpeed: 180.0,
+
+		PythonAPI: StageConfig{
+			Name:     "Python API",
+			Pos:      Point{X: 250, Y: 200},
+			Capacity: 1,
+			Service:  state.ServiceTime{Dist: state.Uniform, Mean: processingDelay, Spread: 150 * time.Millisecond},
+		},
+		RabbitMQ: StageConfig{
+			Name:     "RabbitMQ",
+			Pos:      Point{X: 400, Y: 200},
+			Capacity: 2,
+			Service:  state.ServiceTime{Dist: state.Exponential, Mean: processingDelay},
+		},
+		WebsocketAPI: StageConfig{
+			Name:     "WebSocket API",
+			Pos:      Point{X: 550, Y: 200},
+			Capacity: 1,
+			Service:  state.ServiceTime{Dist: state.Fixed, Mean: processingDelay},
+		},
+		Monitor: Point{X: 620, Y: 180},
+
+		QueueOffsetY:     100,
+		QueueSlotSpacing: 28,
+
+		Devices: Devices(1),
+
+		TFLunaURL: "http://localhost:8000/tfluna/sensor",
+		MPUURL:    "http://localhost:8000/mpu/sensor",
+		IMXURL:    "http://localhost:8000/imx477/sensor",
+
+		Schema:   simulation.SchemaV1,
+		Encoding: codec.JSON,
+
+		Stream: StreamConfig{
+			Interval: 800 * time.Millisecond,
+			Lag:      500 * time.Millisecond,
+			Noise:    0.15,
+		},
+		Batch: BatchConfig{Format: simulation.BatchJSON},
+	}
+}
@@ -0,0 +1,203 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"geova-simulation/state"
+	"image/color"
+	"os"
+	"path"
+	"strings"
+)
+
+// Sensor identifica un sensor del Geova; es también el prefijo del ID de sus
+// paquetes ("geova1/tfluna").
+type Sensor string
+
+const (
+	TFLuna Sensor = "tfluna"
+	MPU    Sensor = "mpu"
+	IMX    Sensor = "imx"
+)
+
+// AllSensors es el juego completo de sensores de un Geova.
+var AllSensors = []Sensor{TFLuna, MPU, IMX}
+
+// ParseSensor valida el nombre de un sensor.
+func ParseSensor(s string) (Sensor, error) {
+	for _, sensor := range AllSensors {
+		if string(sensor) == s {
+			return sensor, nil
+		}
+	}
+	return "", fmt.Errorf("sensor desconocido '%s' (opciones: %v)", s, AllSensors)
+}
+
+// SensorOf retorna el sensor de un ID de paquete: "geova1/mpu" o, en
+// streaming, "geova1/mpu-12".
+func SensorOf(packetID string) Sensor {
+	kind, _, _ := strings.Cut(path.Base(packetID), "-")
+	return Sensor(kind)
+}
+
+// sensorColor tiñe los paquetes según el sensor que los generó y
+// sensorOffsetY separa su punto de salida respecto a la posición del
+// dispositivo.
+var (
+	sensorColor = map[Sensor]color.RGBA{
+		TFLuna: {R: 255, G: 50, B: 50, A: 255},
+		MPU:    {R: 50, G: 150, B: 255, A: 255},
+		IMX:    {R: 50, G: 255, B: 50, A: 255},
+	}
+	sensorOffsetY = map[Sensor]float64{
+		TFLuna: -20,
+		MPU:    0,
+		IMX:    20,
+	}
+)
+
+// DeviceConfig describe un Geova de la escena: el proyecto al que reporta,
+// desde dónde salen sus paquetes, la inclinación inicial del trípode y qué
+// sensores envía.
+type DeviceConfig struct {
+	ID          string
+	Name        string
+	ProjectID   int
+	Pos         Point
+	Tilt, Pitch float64
+	Sensors     []Sensor
+	Color       color.RGBA
+}
+
+func (d DeviceConfig) Has(s Sensor) bool {
+	for _, sensor := range d.Sensors {
+		if sensor == s {
+			return true
+		}
+	}
+	return false
+}
+
+// deviceColors distingue a cada dispositivo en la escena; el primero es
+// neutro para que con un solo Geova la escena se vea como siempre.
+var deviceColors = []color.RGBA{
+	{R: 235, G: 235, B: 235, A: 255},
+	{R: 255, G: 210, B: 60, A: 255},
+	{R: 230, G: 110, B: 255, A: 255},
+	{R: 80, G: 230, B: 230, A: 255},
+	{R: 255, G: 150, B: 70, A: 255},
+	{R: 255, G: 120, B: 170, A: 255},
+}
+
+// DeviceSpacing es la separación vertical entre dispositivos consecutivos.
+const DeviceSpacing = 110
+
+// MaxDevices acota la cantidad de Geova: con DeviceSpacing, más trípodes no
+// entran en la altura de la escena.
+const MaxDevices = 6
+
+// Devices crea n Geova con todos los sensores, apilados hacia abajo desde la
+// posición del trípode original. Los proyectos se numeran desde 4, el ID que
+// usaba la simulación con un solo dispositivo.
+func Devices(n int) []DeviceConfig {
+	devices := make([]DeviceConfig, n)
+	for i := range devices {
+		devices[i] = DeviceConfig{
+			ID:        fmt.Sprintf("geova%d", i+1),
+			Name:      fmt.Sprintf("Geova %d", i+1),
+			ProjectID: 4 + i,
+			Pos:       Point{X: 80, Y: 200 + float64(i)*DeviceSpacing},
+			Sensors:   AllSensors,
+			Color:     deviceColors[i%len(deviceColors)],
+		}
+	}
+	return devices
+}
+
+// deviceSpec es un dispositivo en el archivo de -device-config.
+type deviceSpec struct {
+	ProjectID int      `json:"project_id"`
+	Sensors   []Sensor `json:"sensors"`
+}
+
+// LoadDevices crea los dispositivos de la escena: n como los de Devices y, si
+// path no está vacío, les aplica el archivo JSON en path, una entrada por
+// dispositivo en orden. Los campos que no aparecen conservan su valor y las
+// entradas de más agregan dispositivos:
+//
+//	[
+//	  {"project_id": 12, "sensors": ["tfluna", "imx"]},
+//	  {"project_id": 12, "sensors": ["mpu"]}
+//	]
+func LoadDevices(n int, path string) ([]DeviceConfig, error) {
+	var entries []json.RawMessage
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	n = max(n, len(entries))
+	if n < 1 || n > MaxDevices {
+		return nil, fmt.Errorf("la cantidad de dispositivos debe estar entre 1 y %d", MaxDevices)
+	}
+
+	devices := Devices(n)
+	for i, raw := range entries {
+		// Sensors arranca en nil: Decode reusaría el arreglo de AllSensors
+		spec := deviceSpec{ProjectID: devices[i].ProjectID}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&spec); err != nil {
+			return nil, fmt.Errorf("%s: dispositivo %d: %w", path, i+1, err)
+		}
+		if spec.Sensors == nil {
+			spec.Sensors = devices[i].Sensors
+		}
+		if err := spec.apply(&devices[i]); err != nil {
+			return nil, fmt.Errorf("%s: dispositivo %d: %w", path, i+1, err)
+		}
+	}
+	return devices, nil
+}
+
+// apply valida el dispositivo del archivo y lo copia en d.
+func (s deviceSpec) apply(d *DeviceConfig) error {
+	if s.ProjectID < 1 {
+		return fmt.Errorf("project_id debe ser mayor que 0")
+	}
+	if len(s.Sensors) == 0 {
+		return fmt.Errorf("sensors no puede estar vacío")
+	}
+	seen := make(map[Sensor]bool, len(s.Sensors))
+	for _, sensor := range s.Sensors {
+		if _, err := ParseSensor(string(sensor)); err != nil {
+			return err
+		}
+		if seen[sensor] {
+			return fmt.Errorf("sensor repetido '%s'", sensor)
+		}
+		seen[sensor] = true
+	}
+	d.ProjectID, d.Sensors = s.ProjectID, s.Sensors
+	return nil
+}
+
+func newDeviceStates(devices []DeviceConfig) []*state.DeviceState {
+	states := make([]*state.DeviceState, len(devices))
+	for i, d := range devices {
+		states[i] = &state.DeviceState{
+			ID:        d.ID,
+			Name:      d.Name,
+			ProjectID: d.ProjectID,
+			Color:     d.Color,
+			Tilt:      d.Tilt,
+			Pitch:     d.Pitch,
+		}
+	}
+	return states
+}
@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadDevices(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "devices.json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	devices, err := LoadDevices(1, write(`[
+		{"project_id": 12, "sensors": ["tfluna", "imx"]},
+		{"sensors": ["mpu"]},
+		{}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 3 {
+		t.Fatalf("dispositivos = %d, want 3", len(devices))
+	}
+	for i, want := range []struct {
+		project int
+		sensors []Sensor
+	}{
+		{12, []Sensor{TFLuna, IMX}},
+		{5, []Sensor{MPU}},
+		{6, []Sensor{TFLuna, MPU, IMX}},
+	} {
+		if d := devices[i]; d.ProjectID != want.project || !reflect.DeepEqual(d.Sensors, want.sensors) {
+			t.Errorf("%s: proyecto %d sensores %v, want %d %v", d.ID, d.ProjectID, d.Sensors, want.project, want.sensors)
+		}
+	}
+	if !reflect.DeepEqual(AllSensors, []Sensor{TFLuna, MPU, IMX}) {
+		t.Errorf("AllSensors cambió: %v", AllSensors)
+	}
+
+	if devices, err := LoadDevices(4, write(`[{"sensors": ["mpu"]}]`)); err != nil || len(devices) != 4 {
+		t.Errorf("-devices 4 con una entrada: %d dispositivos, %v; want 4", len(devices), err)
+	}
+	for _, n := range []int{0, -1, MaxDevices + 1} {
+		if _, err := LoadDevices(n, ""); err == nil {
+			t.Errorf("LoadDevices(%d) no retornó error", n)
+		}
+	}
+
+	for src, want := range map[string]string{
+		`[{}, {}, {}, {}, {}, {}, {}]`:  "debe estar entre 1 y 6",
+		`[{"project_id": 0}]`:           "project_id debe ser mayor que 0",
+		`[{"sensors": []}]`:             "sensors no puede estar vacío",
+		`[{"sensors": ["lidar"]}]`:      "sensor desconocido 'lidar'",
+		`[{"sensors": ["mpu", "mpu"]}]`: "sensor repetido 'mpu'",
+		`[{"proyecto": 3}]`:             `unknown field "proyecto"`,
+		`{"project_id": 3}`:             "cannot unmarshal object",
+	} {
+		if _, err := LoadDevices(1, write(src)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadDevices(%s) = %v, want error con %q", src, err, want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package pipeline
+
+import (
+	"geova-simulation/simulation"
+	"geova-simulation/state"
+	"math"
+	"sort"
+	"time"
+)
+
+// updatePacketFSM avanza todos los paquetes dt de tiempo simulado.
+func (p *Pipeline) updatePacketFSM(dt time.Duration) {
+	p.State.Mutex.Lock()
+	defer p.State.Mutex.Unlock()
+
+	if p.State.SimulacionIniciada {
+		p.State.PythonAPI.Tick(dt)
+		p.State.RabbitMQ.Tick(dt)
+		p.State.WebsocketAPI.Tick(dt)
+	}
+
+	allDone := true
+
+	for _, packet := range p.State.Packets {
+		if packet.Status == state.Error || packet.Status == state.Done {
+			continue
+		}
+
+		allDone = false
+		p.advancePacket(packet, dt)
+	}
+
+	if p.State.Streaming {
+		p.pruneFinished()
+	} else if allDone && len(p.State.Packets) > 0 {
+		p.State.SimulacionIniciada = false
+	}
+}
+
+// advancePacket mueve el paquete dt hacia su destino y, al llegar, lo pasa
+// por la FSM. El tiempo que sobra al llegar o al terminar un servicio se
+// sigue usando en el mismo frame, así que el recorrido dura lo mismo a
+// cualquier tasa de ticks.
+func (p *Pipeline) advancePacket(packet *state.PacketState, dt time.Duration) {
+	for dt > 0 {
+		// El worker crea el paquete con el destino por defecto; si la escena
+		// cambió de tamaño la API puede estar en otro lugar.
+		if packet.Status == state.SendingToAPI {
+			p.retarget(packet)
+		}
+
+		dx := packet.TargetX - packet.X
+		dy := packet.TargetY - packet.Y
+		distance := math.Sqrt(dx*dx + dy*dy)
+
+		step := p.Config.PacketSpeed * dt.Seconds()
+		if distance > step {
+			packet.X += (dx / distance) * step
+			packet.Y += (dy / distance) * step
+			return
+		}
+		packet.X = packet.TargetX
+		packet.Y = packet.TargetY
+		if distance > 0 {
+			dt -= time.Duration(distance / p.Config.PacketSpeed * float64(time.Second))
+		}
+
+		status := packet.Status
+		left := p.handlePacketArrival(packet, dt)
+		if packet.Status == status && left == dt {
+			return // Espera la respuesta HTTP o un slot libre
+		}
+		dt = left
+	}
+}
+
+// maxFinishedPackets es cuántos paquetes terminados se conservan en modo
+// streaming (para el inspector) antes de descartar los más viejos.
+const maxFinishedPackets = 30
+
+func (p *Pipeline) pruneFinished() {
+	var finished []*state.PacketState
+	for _, packet := range p.State.Packets {
+		if packet.Status == state.Done || packet.Status == state.Error {
+			finished = append(finished, packet)
+		}
+	}
+	if len(finished) <= maxFinishedPackets {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finishedAt(finished[i]).Before(finishedAt(finished[j]))
+	})
+	for _, packet := range finished[:len(finished)-maxFinishedPackets] {
+		delete(p.State.Packets, packet.ID)
+	}
+}
+
+func finishedAt(packet *state.PacketState) time.Time {
+	if n := len(packet.Transitions); n > 0 {
+		return packet.Transitions[n-1].At
+	}
+	return time.Time{}
+}
+
+// handlePacketArrival aplica la transición del paquete parado en su destino
+// y retorna cuánto de dt no usó el servicio en curso.
+func (p *Pipeline) handlePacketArrival(packet *state.PacketState, dt time.Duration) time.Duration {
+	var served bool
+	switch packet.Status {
+	case state.SendingToAPI:
+
+	case state.ArrivedAtAPI:
+		p.enterStage(p.State.PythonAPI, packet, state.QueuedAtAPI, state.ProcessingAtAPI)
+
+	case state.ProcessingAtAPI:
+		if dt, served = serve(packet, dt); served {
+			p.leaveStage(p.State.PythonAPI, packet, state.ProcessingAtAPI)
+			packet.SetStatus(state.SendingToRabbit, p.Clock.Now())
+			packet.TargetX = p.Config.RabbitMQ.Pos.X
+			packet.TargetY = p.Config.RabbitMQ.Pos.Y
+		}
+
+	case state.SendingToRabbit:
+		p.enterStage(p.State.RabbitMQ, packet, state.QueuedAtRabbit, state.ProcessingAtRabbit)
+
+	case state.ProcessingAtRabbit:
+		if dt, served = serve(packet, dt); served {
+			p.leaveStage(p.State.RabbitMQ, packet, state.ProcessingAtRabbit)
+			packet.SetStatus(state.SendingToWebsocket, p.Clock.Now())
+			packet.TargetX = p.Config.WebsocketAPI.Pos.X
+			packet.TargetY = p.Config.WebsocketAPI.Pos.Y
+		}
+
+	case state.SendingToWebsocket:
+		p.enterStage(p.State.WebsocketAPI, packet, state.QueuedAtWebsocket, state.ProcessingAtWebsocket)
+
+	case state.ProcessingAtWebsocket:
+		if dt, served = serve(packet, dt); served {
+			p.leaveStage(p.State.WebsocketAPI, packet, state.ProcessingAtWebsocket)
+			packet.SetStatus(state.SendingToFrontend, p.Clock.Now())
+			packet.TargetX = p.Config.Monitor.X
+			packet.TargetY = p.Config.Monitor.Y
+		}
+
+	case state.SendingToFrontend:
+		if packet.X == packet.TargetX && packet.Y == packet.TargetY {
+			packet.SetStatus(state.Done, p.Clock.Now())
+			packet.Active = false
+			p.updateDashboard(packet)
+		}
+	}
+	return dt
+}
+
+// serve descuenta dt del servicio del paquete; retorna el tiempo que sobra
+// y si el servicio terminó.
+func serve(packet *state.PacketState, dt time.Duration) (time.Duration, bool) {
+	if dt < packet.ProcessingTimer {
+		packet.ProcessingTimer -= dt
+		return 0, false
+	}
+	dt -= packet.ProcessingTimer
+	packet.ProcessingTimer = 0
+	return dt, true
+}
+
+// enterStage ocupa un slot libre de la etapa o, si está llena, pone el
+// paquete al final de la cola visible bajo el icono.
+func (p *Pipeline) enterStage(stage *state.StageState, packet *state.PacketState,
+	queued, processing state.PacketStatus) {
+	if stage.HasFreeSlot() {
+		p.startService(stage, packet, processing)
+		return
+	}
+	stage.Queue = append(stage.Queue, packet)
+	packet.SetStatus(queued, p.Clock.Now())
+	p.layoutQueue(stage)
+}
+
+// leaveStage libera el slot del paquete y da paso al primero de la cola.
+func (p *Pipeline) leaveStage(stage *state.StageState, packet *state.PacketState,
+	processing state.PacketStatus) {
+	stage.Remove(packet)
+	if next := stage.Dequeue(); next != nil {
+		p.startService(stage, next, processing)
+		p.layoutQueue(stage)
+	}
+}
+
+func (p *Pipeline) startService(stage *state.StageState, packet *state.PacketState, processing state.PacketStatus) {
+	stage.InService = append(stage.InService, packet)
+	packet.SetStatus(processing, p.Clock.Now())
+	packet.ProcessingTimer = stage.Service.Sample()
+	packet.TargetX = stage.X
+	packet.TargetY = stage.Y
+}
+
+func (p *Pipeline) layoutQueue(stage *state.StageState) {
+	for i, queued := range stage.Queue {
+		queued.TargetX = stage.X + 16
+		queued.TargetY = stage.Y + p.Config.QueueOffsetY + float64(i)*p.Config.QueueSlotSpacing
+	}
+}
+
+// updateDashboard muestra los datos del paquete en la sección de su
+// dispositivo.
+func (p *Pipeline) updateDashboard(packet *state.PacketState) {
+	d := p.State.Device(packet.Device)
+	if d == nil {
+		return
+	}
+	if batch, ok := packet.Payload.(simulation.Batch); ok {
+		for _, item := range batch.Items {
+			showReading(d, item)
+		}
+		return
+	}
+	showReading(d, packet.Payload)
+}
+
+// showReading pasa una lectura al dashboard y al historial del dispositivo.
+func showReading(d *state.DeviceState, payload interface{}) {
+	h := &d.History
+	switch data := payload.(type) {
+	case simulation.TFLunaData:
+		d.DisplayDistancia = data.DistanciaM
+		h[state.MetricDistancia].Add(data.DistanciaM)
+		h[state.MetricFuerzaSenal].Add(float64(data.FuerzaSenal))
+		h[state.MetricTemperatura].Add(data.Temperatura)
+	case simulation.MPUData:
+		d.DisplayRoll = data.Roll
+		d.DisplayPitch = data.Pitch
+		h[state.MetricRoll].Add(data.Roll)
+		h[state.MetricPitch].Add(data.Pitch)
+	case simulation.IMXData:
+		d.DisplayNitidez = data.Nitidez
+		d.DisplayLaser = data.LaserDetectado
+		h[state.MetricNitidez].Add(data.Nitidez)
+	}
+}
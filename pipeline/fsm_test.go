@@ -0,0 +1,436 @@
+package pipeline
+
+import (
+	"fmt"
+	"geova-simulation/clock"
+	"geova-simulation/simulation"
+	"geova-simulation/state"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+const testService = 400 * time.Millisecond
+
+// testConfig usa tiempos de servicio fijos para que las transiciones sean
+// deterministas.
+func testConfig() Config {
+	cfg := DefaultConfig()
+	fixed := state.ServiceTime{Dist: state.Fixed, Mean: testService}
+	cfg.PythonAPI.Service = fixed
+	cfg.RabbitMQ.Service = fixed
+	cfg.WebsocketAPI.Service = fixed
+	cfg.RabbitMQ.Capacity = 1
+	return cfg
+}
+
+func newTestPipeline() (*Pipeline, *clock.Fake) {
+	clk := clock.NewFake(epoch)
+	visState := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+	return New(visState, clk, testConfig()), clk
+}
+
+// fill ocupa todos los slots de una etapa con paquetes de relleno.
+func fill(stage *state.StageState) {
+	for stage.HasFreeSlot() {
+		stage.InService = append(stage.InService, &state.PacketState{ID: "relleno"})
+	}
+}
+
+func TestHandlePacketArrival(t *testing.T) {
+	cfg := testConfig()
+
+	tests := []struct {
+		name    string
+		status  state.PacketStatus
+		timer   time.Duration
+		payload interface{}
+		setup   func(p *Pipeline)
+
+		wantStatus state.PacketStatus
+		wantTimer  time.Duration
+		wantTarget Point
+		wantActive bool
+		check      func(t *testing.T, p *Pipeline, packet *state.PacketState)
+	}{
+		{
+			name:       "SendingToAPI espera la respuesta HTTP",
+			status:     state.SendingToAPI,
+			wantStatus: state.SendingToAPI,
+			wantTarget: cfg.PythonAPI.Pos,
+			wantActive: true,
+		},
+		{
+			name:       "ArrivedAtAPI con slot libre empieza a procesar",
+			status:     state.ArrivedAtAPI,
+			wantStatus: state.ProcessingAtAPI,
+			wantTimer:  testService,
+			wantTarget: cfg.PythonAPI.Pos,
+			wantActive: true,
+			check: func(t *testing.T, p *Pipeline, packet *state.PacketState) {
+				if len(p.State.PythonAPI.InService) != 1 || p.State.PythonAPI.InService[0] != packet {
+					t.Errorf("el paquete debería ocupar el slot de la API")
+				}
+			},
+		},
+		{
+			name:       "ArrivedAtAPI con la etapa llena entra a la cola",
+			status:     state.ArrivedAtAPI,
+			setup:      func(p *Pipeline) { fill(p.State.PythonAPI) },
+			wantStatus: state.QueuedAtAPI,
+			wantTarget: Point{X: cfg.PythonAPI.Pos.X + 16, Y: cfg.PythonAPI.Pos.Y + cfg.QueueOffsetY},
+			wantActive: true,
+			check: func(t *testing.T, p *Pipeline, packet *state.PacketState) {
+				if len(p.State.PythonAPI.Queue) != 1 {
+					t.Errorf("cola de la API = %d, want 1", len(p.State.PythonAPI.Queue))
+				}
+			},
+		},
+		{
+			name:       "QueuedAtAPI sigue esperando",
+			status:     state.QueuedAtAPI,
+			wantStatus: state.QueuedAtAPI,
+			wantTarget: cfg.PythonAPI.Pos,
+			wantActive: true,
+		},
+		{
+			name:       "ProcessingAtAPI descuenta el timer",
+			status:     state.ProcessingAtAPI,
+			timer:      testService,
+			wantStatus: state.ProcessingAtAPI,
+			wantTimer:  testService - frame,
+			wantTarget: cfg.PythonAPI.Pos,
+			wantActive: true,
+		},
+		{
+			name:       "ProcessingAtAPI terminado va a RabbitMQ",
+			status:     state.ProcessingAtAPI,
+			wantStatus: state.SendingToRabbit,
+			wantTarget: cfg.RabbitMQ.Pos,
+			wantActive: true,
+		},
+		{
+			name:       "SendingToRabbit con slot libre empieza a procesar",
+			status:     state.SendingToRabbit,
+			wantStatus: state.ProcessingAtRabbit,
+			wantTimer:  testService,
+			wantTarget: cfg.RabbitMQ.Pos,
+			wantActive: true,
+		},
+		{
+			name:       "SendingToRabbit con la etapa llena entra a la cola",
+			status:     state.SendingToRabbit,
+			setup:      func(p *Pipeline) { fill(p.State.RabbitMQ) },
+			wantStatus: state.QueuedAtRabbit,
+			wantTarget: Point{X: cfg.RabbitMQ.Pos.X + 16, Y: cfg.RabbitMQ.Pos.Y + cfg.QueueOffsetY},
+			wantActive: true,
+		},
+		{
+			name:       "ProcessingAtRabbit terminado va al WebSocket",
+			status:     state.ProcessingAtRabbit,
+			wantStatus: state.SendingToWebsocket,
+			wantTarget: cfg.WebsocketAPI.Pos,
+			wantActive: true,
+		},
+		{
+			name:       "SendingToWebsocket con slot libre empieza a procesar",
+			status:     state.SendingToWebsocket,
+			wantStatus: state.ProcessingAtWebsocket,
+			wantTimer:  testService,
+			wantTarget: cfg.WebsocketAPI.Pos,
+			wantActive: true,
+		},
+		{
+			name:       "SendingToWebsocket con la etapa llena entra a la cola",
+			status:     state.SendingToWebsocket,
+			setup:      func(p *Pipeline) { fill(p.State.WebsocketAPI) },
+			wantStatus: state.QueuedAtWebsocket,
+			wantTarget: Point{X: cfg.WebsocketAPI.Pos.X + 16, Y: cfg.WebsocketAPI.Pos.Y + cfg.QueueOffsetY},
+			wantActive: true,
+		},
+		{
+			name:       "ProcessingAtWebsocket terminado va al frontend",
+			status:     state.ProcessingAtWebsocket,
+			wantStatus: state.SendingToFrontend,
+			wantTarget: cfg.Monitor,
+			wantActive: true,
+		},
+		{
+			name:       "SendingToFrontend termina y actualiza el dashboard",
+			status:     state.SendingToFrontend,
+			payload:    simulation.TFLunaData{DistanciaM: 1.75, Temperatura: 52},
+			wantStatus: state.Done,
+			wantTarget: cfg.Monitor,
+			wantActive: false,
+			check: func(t *testing.T, p *Pipeline, packet *state.PacketState) {
+				d := p.State.Devices[0]
+				if d.DisplayDistancia != 1.75 {
+					t.Errorf("DisplayDistancia = %v, want 1.75", d.DisplayDistancia)
+				}
+				if got := d.History[state.MetricTemperatura].Values(); len(got) != 1 || got[0] != 52 {
+					t.Errorf("historial de temperatura = %v, want [52]", got)
+				}
+			},
+		},
+		{
+			name:       "SendingToFrontend con IMX actualiza el láser",
+			status:     state.SendingToFrontend,
+			payload:    simulation.IMXData{Nitidez: 5.1, LaserDetectado: true},
+			wantStatus: state.Done,
+			wantTarget: cfg.Monitor,
+			wantActive: false,
+			check: func(t *testing.T, p *Pipeline, packet *state.PacketState) {
+				d := p.State.Devices[0]
+				if !d.DisplayLaser || d.DisplayNitidez != 5.1 {
+					t.Errorf("DisplayLaser = %v, DisplayNitidez = %v, want true, 5.1",
+						d.DisplayLaser, d.DisplayNitidez)
+				}
+			},
+		},
+		{
+			name:       "Done no cambia",
+			status:     state.Done,
+			wantStatus: state.Done,
+			wantTarget: cfg.Monitor,
+			wantActive: false,
+		},
+		{
+			name:       "Error no cambia",
+			status:     state.Error,
+			wantStatus: state.Error,
+			wantTarget: cfg.PythonAPI.Pos,
+			wantActive: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newTestPipeline()
+			if tt.setup != nil {
+				tt.setup(p)
+			}
+
+			// El paquete está parado sobre el destino que corresponde a su
+			// estado, como cuando updatePacketFSM llama a handlePacketArrival.
+			target := arrivalTarget(cfg, tt.status)
+			packet := &state.PacketState{
+				ID:              "pkt",
+				Active:          tt.status != state.Done,
+				X:               target.X,
+				Y:               target.Y,
+				TargetX:         target.X,
+				TargetY:         target.Y,
+				Status:          tt.status,
+				Payload:         tt.payload,
+				ProcessingTimer: tt.timer,
+				Device:          "geova1",
+			}
+			if stage := processingStage(p, tt.status); stage != nil {
+				stage.InService = append(stage.InService, packet)
+			}
+			p.State.Packets[packet.ID] = packet
+
+			p.handlePacketArrival(packet, frame)
+
+			if packet.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", packet.Status, tt.wantStatus)
+			}
+			if packet.ProcessingTimer != tt.wantTimer {
+				t.Errorf("ProcessingTimer = %v, want %v", packet.ProcessingTimer, tt.wantTimer)
+			}
+			if packet.TargetX != tt.wantTarget.X || packet.TargetY != tt.wantTarget.Y {
+				t.Errorf("Target = (%v, %v), want (%v, %v)",
+					packet.TargetX, packet.TargetY, tt.wantTarget.X, tt.wantTarget.Y)
+			}
+			if packet.Active != tt.wantActive {
+				t.Errorf("Active = %v, want %v", packet.Active, tt.wantActive)
+			}
+			if tt.check != nil {
+				tt.check(t, p, packet)
+			}
+		})
+	}
+}
+
+func TestLeaveStageAdmitsQueuedPacket(t *testing.T) {
+	p, _ := newTestPipeline()
+	stage := p.State.PythonAPI
+
+	first := &state.PacketState{ID: "a", Status: state.ArrivedAtAPI}
+	second := &state.PacketState{ID: "b", Status: state.ArrivedAtAPI}
+	p.handlePacketArrival(first, frame)
+	p.handlePacketArrival(second, frame)
+
+	if second.Status != state.QueuedAtAPI {
+		t.Fatalf("segundo paquete: Status = %v, want QueuedAtAPI", second.Status)
+	}
+
+	first.ProcessingTimer = 0
+	p.handlePacketArrival(first, frame)
+
+	if second.Status != state.ProcessingAtAPI {
+		t.Errorf("segundo paquete: Status = %v, want ProcessingAtAPI", second.Status)
+	}
+	if len(stage.Queue) != 0 || len(stage.InService) != 1 || stage.InService[0] != second {
+		t.Errorf("etapa: InService=%d Queue=%d, want el segundo paquete en servicio",
+			len(stage.InService), len(stage.Queue))
+	}
+}
+
+func TestTickFollowsClock(t *testing.T) {
+	p, clk := newTestPipeline()
+	// Los paquetes en SendingToAPI siempre van hacia la API
+	p.Config.PythonAPI.Pos = Point{X: 1000, Y: 0}
+	p.State.Packets["pkt"] = &state.PacketState{
+		ID: "pkt", Active: true,
+		X: 0, Y: 0, TargetX: 1000, TargetY: 0,
+		Status: state.SendingToAPI,
+	}
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		scale   float64
+		wantX   float64
+	}{
+		{"pausa descarta el tiempo", 200 * time.Millisecond, 0, 0},
+		{"tiempo real", 100 * time.Millisecond, 1, 18},
+		{"doble velocidad", 100 * time.Millisecond, 2, 54},
+		{"salto largo acotado", 10 * time.Second, 1, 54 + 45},
+	}
+
+	for _, tt := range tests {
+		clk.Advance(tt.advance)
+		p.Tick(tt.scale)
+		if got := p.State.Packets["pkt"].X; !almostEqual(got, tt.wantX) {
+			t.Errorf("%s: X = %v, want %v", tt.name, got, tt.wantX)
+		}
+	}
+}
+
+// TestCompletionIndependentOfTickRate recorre todo el pipeline a 30 y a 240
+// ticks por segundo: el tiempo que sobra al llegar a cada destino y al
+// terminar cada servicio se sigue usando, así que el paquete termina en el
+// mismo instante salvo por la resolución del último frame.
+func TestCompletionIndependentOfTickRate(t *testing.T) {
+	finish := func(tps int) time.Duration {
+		p, clk := newTestPipeline()
+		p.State.SimulacionIniciada = true
+		packet := &state.PacketState{
+			ID: "pkt", Active: true, X: 100, Y: 300,
+			TargetX: p.Config.PythonAPI.Pos.X, TargetY: p.Config.PythonAPI.Pos.Y,
+			Status: state.ArrivedAtAPI,
+		}
+		p.State.Packets[packet.ID] = packet
+
+		tick := time.Second / time.Duration(tps)
+		for packet.Status != state.Done {
+			if clk.Now().Sub(epoch) > time.Minute {
+				t.Fatalf("%d TPS: el paquete no terminó (%v)", tps, packet.Status)
+			}
+			clk.Advance(tick)
+			p.Tick(1)
+		}
+		return clk.Now().Sub(epoch)
+	}
+
+	slow, fast := finish(30), finish(240)
+	if d := (slow - fast).Abs(); d > time.Second/30 {
+		t.Errorf("terminó a los %v con 30 TPS y a los %v con 240 TPS", slow, fast)
+	}
+}
+
+const frame = time.Second / 60
+
+func almostEqual(a, b float64) bool {
+	d := a - b
+	return d < 1e-6 && d > -1e-6
+}
+
+// arrivalTarget devuelve dónde está parado un paquete al llegar en cada estado.
+func arrivalTarget(cfg Config, status state.PacketStatus) Point {
+	switch status {
+	case state.SendingToRabbit, state.ProcessingAtRabbit, state.QueuedAtRabbit:
+		return cfg.RabbitMQ.Pos
+	case state.SendingToWebsocket, state.ProcessingAtWebsocket, state.QueuedAtWebsocket:
+		return cfg.WebsocketAPI.Pos
+	case state.SendingToFrontend, state.Done:
+		return cfg.Monitor
+	default:
+		return cfg.PythonAPI.Pos
+	}
+}
+
+func processingStage(p *Pipeline, status state.PacketStatus) *state.StageState {
+	switch status {
+	case state.ProcessingAtAPI:
+		return p.State.PythonAPI
+	case state.ProcessingAtRabbit:
+		return p.State.RabbitMQ
+	case state.ProcessingAtWebsocket:
+		return p.State.WebsocketAPI
+	}
+	return nil
+}
+
+func TestSetPositionsKeepsPacketsInFlight(t *testing.T) {
+	p, _ := newTestPipeline()
+	toRabbit := &state.PacketState{ID: "a", Active: true, X: 300, Y: 200, Status: state.SendingToRabbit}
+	queued := &state.PacketState{ID: "b", Active: true, Status: state.ArrivedAtAPI}
+	p.State.Packets["a"] = toRabbit
+	p.State.Packets["b"] = queued
+	fill(p.State.PythonAPI)
+	p.handlePacketArrival(queued, frame)
+
+	pos := p.Config.Positions()
+	pos.RabbitMQ = Point{X: 420, Y: 260}
+	pos.PythonAPI = Point{X: 230, Y: 180}
+	p.SetPositions(pos)
+
+	if toRabbit.Status != state.SendingToRabbit || toRabbit.X != 300 {
+		t.Errorf("el paquete en vuelo cambió de estado o posición: %v (%v)", toRabbit.Status, toRabbit.X)
+	}
+	if toRabbit.TargetX != 420 || toRabbit.TargetY != 260 {
+		t.Errorf("destino = (%v, %v), want (420, 260)", toRabbit.TargetX, toRabbit.TargetY)
+	}
+	if queued.Status != state.QueuedAtAPI || queued.TargetX != 230+16 || queued.TargetY != 180+p.Config.QueueOffsetY {
+		t.Errorf("paquete en cola: %v en (%v, %v)", queued.Status, queued.TargetX, queued.TargetY)
+	}
+	if p.State.RabbitMQ.X != 420 || p.State.RabbitMQ.Y != 260 {
+		t.Errorf("etapa RabbitMQ en (%v, %v), want (420, 260)", p.State.RabbitMQ.X, p.State.RabbitMQ.Y)
+	}
+}
+
+func TestStreamingKeepsRunningAndPrunesFinished(t *testing.T) {
+	p, _ := newTestPipeline()
+	p.State.SimulacionIniciada = true
+	p.State.Streaming = true
+
+	total := maxFinishedPackets + 5
+	for i := 0; i < total; i++ {
+		packet := &state.PacketState{ID: fmt.Sprintf("mpu-%d", i)}
+		packet.SetStatus(state.Done, epoch.Add(time.Duration(i)*time.Second))
+		p.State.Packets[packet.ID] = packet
+	}
+	p.updatePacketFSM(frame)
+
+	if !p.State.SimulacionIniciada {
+		t.Error("la simulación terminó aunque el streaming sigue activo")
+	}
+	if len(p.State.Packets) != maxFinishedPackets {
+		t.Errorf("paquetes = %d, want %d", len(p.State.Packets), maxFinishedPackets)
+	}
+	if _, ok := p.State.Packets["mpu-4"]; ok {
+		t.Error("mpu-4 debía descartarse por ser de los más viejos")
+	}
+	if _, ok := p.State.Packets["mpu-5"]; !ok {
+		t.Error("mpu-5 no debía descartarse")
+	}
+
+	p.State.Streaming = false
+	p.updatePacketFSM(frame)
+	if p.State.SimulacionIniciada {
+		t.Error("la simulación sigue activa tras detener el streaming")
+	}
+}
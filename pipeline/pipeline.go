@@ -0,0 +1,365 @@
+package pipeline
+
+import (
+	"fmt"
+	"geova-simulation/clock"
+	"geova-simulation/simulation"
+	"geova-simulation/state"
+	"time"
+)
+
+// maxFrameDelta limita el salto de tiempo tras una pausa larga del proceso
+// (ventana arrastrada, breakpoint) para que los paquetes no se teletransporten.
+const maxFrameDelta = 250 * time.Millisecond
+
+// Pipeline es la FSM de los paquetes: mueve cada paquete por las etapas del
+// backend en tiempo simulado. No depende de Ebitengine, así que la usan tanto
+// la UI como el runner headless.
+type Pipeline struct {
+	State  *state.VisualState
+	Clock  clock.Clock
+	Config Config
+	Sender *simulation.Sender // Envía las lecturas con el mismo Clock
+
+	lastTick time.Time
+	stream   *stream // Streaming en curso; nil si no hay. Lo protege el mutex del estado
+}
+
+// stream lleva el ritmo de un streaming en tiempo simulado: Step le suma el
+// dt de cada frame, así que se detiene en pausa y sigue la escala de tiempo
+// igual que la FSM.
+type stream struct {
+	sensor   Sensor
+	interval time.Duration
+	start    time.Time     // Inicio en el reloj del pipeline
+	elapsed  time.Duration // Tiempo simulado desde start
+	since    time.Duration // Tiempo simulado desde la última muestra
+	n        int           // Muestras generadas
+	mpus     []*simulation.MPUSensor
+	batches  *batcher // nil si no se agrupa
+}
+
+// now es el instante simulado del streaming; con él vencen las ventanas de
+// los lotes y se filtra el MPU.
+func (s *stream) now() time.Time {
+	return s.start.Add(s.elapsed)
+}
+
+func New(visState *state.VisualState, clk clock.Clock, cfg Config) *Pipeline {
+	sender := simulation.NewSender(clk)
+	sender.Schema, sender.Encoding = cfg.Schema, cfg.Encoding
+	sender.Compression.Encoding = cfg.Compression
+	sender.Auth.SetRules(cfg.Auth)
+	p := &Pipeline{
+		State:    visState,
+		Clock:    clk,
+		Config:   cfg,
+		Sender:   sender,
+		lastTick: clk.Now(),
+	}
+	visState.Devices = newDeviceStates(cfg.Devices)
+	p.resetStages()
+	return p
+}
+
+// Tick lee el tiempo transcurrido desde el último Tick y avanza la simulación
+// ese tiempo multiplicado por scale. Con scale 0 el tiempo se descarta (pausa).
+// Retorna el tiempo simulado que avanzó.
+func (p *Pipeline) Tick(scale float64) time.Duration {
+	now := p.Clock.Now()
+	dt := now.Sub(p.lastTick)
+	p.lastTick = now
+
+	if dt > maxFrameDelta {
+		dt = maxFrameDelta
+	}
+	dt = time.Duration(float64(dt) * scale)
+	p.Step(dt)
+	return dt
+}
+
+// Step avanza la simulación exactamente dt de tiempo simulado.
+func (p *Pipeline) Step(dt time.Duration) {
+	if dt <= 0 {
+		return
+	}
+	p.updatePacketFSM(dt)
+	p.advanceStream(dt)
+}
+
+// Start reinicia el estado y lanza un worker por cada sensor de cada
+// dispositivo.
+func (p *Pipeline) Start() {
+	p.State.Mutex.Lock()
+	p.reset()
+	tilts := make([][2]float64, len(p.State.Devices))
+	for i, d := range p.State.Devices {
+		tilts[i] = [2]float64{d.Tilt, d.Pitch}
+	}
+	p.State.Mutex.Unlock()
+
+	for i, dev := range p.Config.Devices {
+		for _, sensor := range dev.Sensors {
+			payload := reading(dev, sensor, tilts[i][0], tilts[i][1], p.Clock.Now())
+			p.send(dev, sensor, string(sensor), payload)
+		}
+	}
+}
+
+// reading genera una lectura aleatoria de sensor para el dispositivo, tomada
+// en now; roll y pitch solo se usan en el MPU.
+func reading(dev DeviceConfig, sensor Sensor, roll, pitch float64, now time.Time) interface{} {
+	switch sensor {
+	case TFLuna:
+		return simulation.GenerateRandomTFLunaData(dev.ProjectID, now)
+	case MPU:
+		return simulation.GenerateRandomMPUData(dev.ProjectID, roll, pitch, now)
+	case IMX:
+		return simulation.GenerateRandomIMXData(dev.ProjectID, now)
+	}
+	return nil
+}
+
+// send lanza el worker que envía payload; el ID del paquete es el del
+// dispositivo seguido de name ("geova1/mpu-3").
+func (p *Pipeline) send(dev DeviceConfig, sensor Sensor, name string, payload interface{}) {
+	go p.Sender.SendPOSTRequest(p.Config.URL(sensor), payload, dev.ID+"/"+name, p.State, origin(dev, sensor))
+}
+
+// origin es el punto de salida de los paquetes de sensor en el dispositivo.
+func origin(dev DeviceConfig, sensor Sensor) simulation.Origin {
+	return simulation.Origin{
+		Device: dev.ID,
+		X:      dev.Pos.X,
+		Y:      dev.Pos.Y + sensorOffsetY[sensor],
+		Color:  sensorColor[sensor],
+	}
+}
+
+// StartStreaming es el streaming del MPU de la tecla S: una muestra de cada
+// dispositivo cada Config.Stream.Interval.
+func (p *Pipeline) StartStreaming() {
+	p.StartStream(MPU, p.Config.Stream.Interval)
+}
+
+// StartStream reinicia el estado y envía una lectura de sensor de cada
+// dispositivo que lo tenga cada interval de tiempo simulado, hasta
+// StopStreaming. A diferencia de Start, la inclinación se lee en cada
+// muestra del MPU, así que mover el trípode se ve en los datos que siguen
+// llegando. Con Config.Batch activo las lecturas viajan en lotes.
+func (p *Pipeline) StartStream(sensor Sensor, interval time.Duration) {
+	s := &stream{
+		sensor:   sensor,
+		interval: interval,
+		start:    p.Clock.Now(),
+		n:        1,
+		mpus:     make([]*simulation.MPUSensor, len(p.Config.Devices)),
+	}
+	for i, dev := range p.Config.Devices {
+		s.mpus[i] = &simulation.MPUSensor{
+			ProjectID: dev.ProjectID,
+			Lag:       p.Config.Stream.Lag,
+			Noise:     p.Config.Stream.Noise,
+		}
+	}
+	if p.Config.Batch.Enabled() {
+		s.batches = newBatcher(p.Config.Batch)
+	}
+
+	p.State.Mutex.Lock()
+	if p.State.Streaming {
+		p.State.Mutex.Unlock()
+		return
+	}
+	p.reset()
+	p.State.Streaming = true
+	p.stream = s
+	p.State.Mutex.Unlock()
+
+	// La primera muestra sale enseguida; las demás, con Step
+	p.streamSamples(s, 1, s.start)
+}
+
+// advanceStream avanza dt el streaming en curso y genera las muestras que
+// vencieron y los lotes cuya ventana pasó.
+func (p *Pipeline) advanceStream(dt time.Duration) {
+	type sample struct {
+		n  int
+		at time.Time
+	}
+	var samples []sample
+
+	p.State.Mutex.Lock()
+	s := p.stream
+	if s == nil {
+		p.State.Mutex.Unlock()
+		return
+	}
+	end := s.elapsed + dt
+	s.since += dt
+	for s.since >= s.interval {
+		s.since -= s.interval
+		s.n++
+		// La muestra venció s.since antes del final del frame
+		samples = append(samples, sample{s.n, s.start.Add(end - s.since)})
+	}
+	s.elapsed = end
+	now := s.now()
+	p.State.Mutex.Unlock()
+
+	for _, smp := range samples {
+		p.streamSamples(s, smp.n, smp.at)
+	}
+	if s.batches != nil {
+		for _, batch := range s.batches.due(now) {
+			p.sendBatch(batch)
+		}
+	}
+}
+
+// streamSamples genera la muestra n en cada dispositivo que tiene el sensor.
+func (p *Pipeline) streamSamples(s *stream, n int, at time.Time) {
+	for i, dev := range p.Config.Devices {
+		if dev.Has(s.sensor) {
+			p.streamSample(i, s.sensor, s.mpus[i], n, s.batches, at)
+		}
+	}
+}
+
+// StopStreaming deja de generar muestras; las que están en vuelo terminan su
+// recorrido y los lotes a medio llenar se envían como están.
+func (p *Pipeline) StopStreaming() {
+	p.State.Mutex.Lock()
+	s := p.stream
+	if !p.State.Streaming || s == nil {
+		p.State.Mutex.Unlock()
+		return
+	}
+	p.State.Streaming = false
+	p.stream = nil
+	p.State.Mutex.Unlock()
+
+	if s.batches != nil {
+		for _, batch := range s.batches.drain() {
+			p.sendBatch(batch)
+		}
+	}
+}
+
+// streamSample envía la muestra n de sensor del dispositivo i, tomada en el
+// instante simulado at, o la suma a su lote si batches no es nil. Las del
+// MPU pasan por mpu con la inclinación actual del trípode.
+func (p *Pipeline) streamSample(i int, sensor Sensor, mpu *simulation.MPUSensor, n int, batches *batcher, at time.Time) {
+	dev := p.Config.Devices[i]
+	var payload interface{}
+	if sensor == MPU {
+		p.State.Mutex.Lock()
+		d := p.State.Devices[i]
+		roll, pitch := d.Tilt, d.Pitch
+		p.State.Mutex.Unlock()
+		payload = mpu.Sample(roll, pitch, at)
+	} else {
+		payload = reading(dev, sensor, 0, 0, at)
+	}
+	if batches == nil {
+		p.send(dev, sensor, fmt.Sprintf("%s-%d", sensor, n), payload)
+	} else if batch := batches.add(i, sensor, payload, at); batch != nil {
+		p.sendBatch(batch)
+	}
+}
+
+// reset vacía los paquetes y el dashboard. Requiere el mutex.
+func (p *Pipeline) reset() {
+	p.State.Packets = make(map[string]*state.PacketState)
+	for _, d := range p.State.Devices {
+		d.ResetDisplay()
+	}
+	p.State.SimulacionIniciada = true
+	p.resetStages()
+}
+
+func (p *Pipeline) resetStages() {
+	newStage := func(c StageConfig) *state.StageState {
+		return state.NewStage(c.Name, c.Pos.X, c.Pos.Y, c.Capacity, c.Service)
+	}
+	p.State.PythonAPI = newStage(p.Config.PythonAPI)
+	p.State.RabbitMQ = newStage(p.Config.RabbitMQ)
+	p.State.WebsocketAPI = newStage(p.Config.WebsocketAPI)
+}
+
+// Positions son los puntos de salida de los paquetes (uno por dispositivo)
+// y los destinos por los que pasan.
+type Positions struct {
+	Devices      []Point
+	PythonAPI    Point
+	RabbitMQ     Point
+	WebsocketAPI Point
+	Monitor      Point
+}
+
+func (c Config) Positions() Positions {
+	devices := make([]Point, len(c.Devices))
+	for i, d := range c.Devices {
+		devices[i] = d.Pos
+	}
+	return Positions{
+		Devices:      devices,
+		PythonAPI:    c.PythonAPI.Pos,
+		RabbitMQ:     c.RabbitMQ.Pos,
+		WebsocketAPI: c.WebsocketAPI.Pos,
+		Monitor:      c.Monitor,
+	}
+}
+
+// SetPositions mueve las etapas a nuevas coordenadas sin reiniciar la
+// simulación: los paquetes en vuelo conservan su estado y solo cambian de
+// destino.
+func (p *Pipeline) SetPositions(pos Positions) {
+	p.State.Mutex.Lock()
+	defer p.State.Mutex.Unlock()
+
+	p.Config.PythonAPI.Pos = pos.PythonAPI
+	p.Config.RabbitMQ.Pos = pos.RabbitMQ
+	p.Config.WebsocketAPI.Pos = pos.WebsocketAPI
+	p.Config.Monitor = pos.Monitor
+	for i := range p.Config.Devices {
+		if i < len(pos.Devices) {
+			p.Config.Devices[i].Pos = pos.Devices[i]
+		}
+	}
+
+	for _, stage := range []struct {
+		state *state.StageState
+		pos   Point
+	}{
+		{p.State.PythonAPI, pos.PythonAPI},
+		{p.State.RabbitMQ, pos.RabbitMQ},
+		{p.State.WebsocketAPI, pos.WebsocketAPI},
+	} {
+		stage.state.X, stage.state.Y = stage.pos.X, stage.pos.Y
+		p.layoutQueue(stage.state)
+	}
+
+	for _, packet := range p.State.Packets {
+		p.retarget(packet)
+	}
+}
+
+// retarget recalcula el destino de un paquete a partir de su estado.
+func (p *Pipeline) retarget(packet *state.PacketState) {
+	var target Point
+	switch packet.Status {
+	case state.SendingToAPI, state.ArrivedAtAPI, state.ProcessingAtAPI:
+		target = p.Config.PythonAPI.Pos
+	case state.SendingToRabbit, state.ProcessingAtRabbit:
+		target = p.Config.RabbitMQ.Pos
+	case state.SendingToWebsocket, state.ProcessingAtWebsocket:
+		target = p.Config.WebsocketAPI.Pos
+	case state.SendingToFrontend:
+		target = p.Config.Monitor
+	default:
+		// En cola (ya reubicado por layoutQueue), Done o Error.
+		return
+	}
+	packet.TargetX, packet.TargetY = target.X, target.Y
+}
@@ -0,0 +1,308 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"geova-simulation/clock"
+	"geova-simulation/simulation"
+	"geova-simulation/state"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// runHeadless arranca una simulación contra srv y avanza la FSM con un reloj
+// falso hasta que todos los paquetes terminan. Devuelve la secuencia de
+// estados de cada paquete según sus transiciones registradas.
+func runHeadless(t *testing.T, handler http.Handler) (*Pipeline, map[string][]state.PacketStatus) {
+	t.Helper()
+	return runHeadlessConfig(t, testConfig(), handler)
+}
+
+func runHeadlessConfig(t *testing.T, cfg Config, handler http.Handler) (*Pipeline, map[string][]state.PacketStatus) {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	cfg.TFLunaURL = srv.URL + "/tfluna/sensor"
+	cfg.MPUURL = srv.URL + "/mpu/sensor"
+	cfg.IMXURL = srv.URL + "/imx477/sensor"
+
+	clk := clock.NewFake(epoch)
+	visState := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+	p := New(visState, clk, cfg)
+	p.Start()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		clk.Advance(frame)
+		p.Tick(1)
+
+		visState.Mutex.Lock()
+		running := visState.SimulacionIniciada
+		seen := make(map[string][]state.PacketStatus)
+		for id, packet := range visState.Packets {
+			for _, tr := range packet.Transitions {
+				seen[id] = append(seen[id], tr.Status)
+			}
+		}
+		visState.Mutex.Unlock()
+
+		if !running {
+			return p, seen
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("la simulación no terminó; estados vistos: %v", seen)
+		}
+		// Deja correr a los workers HTTP entre frames.
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPacketLifecycle(t *testing.T) {
+	p, seen := runHeadless(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	if len(seen) != 3 {
+		t.Fatalf("paquetes = %d, want 3", len(seen))
+	}
+	for id, history := range seen {
+		if history[0] != state.SendingToAPI {
+			t.Errorf("%s: primer estado = %v, want SendingToAPI", id, history[0])
+		}
+		for i := 1; i < len(history); i++ {
+			if history[i] < history[i-1] {
+				t.Errorf("%s: transición hacia atrás %v -> %v", id, history[i-1], history[i])
+			}
+		}
+		for _, want := range []state.PacketStatus{
+			state.ProcessingAtAPI, state.ProcessingAtRabbit, state.ProcessingAtWebsocket,
+			state.SendingToFrontend, state.Done,
+		} {
+			if !containsStatus(history, want) {
+				t.Errorf("%s: nunca pasó por %v (historial %v)", id, want, history)
+			}
+		}
+	}
+
+	// Workers y FSM marcan las transiciones con el mismo reloj, así que van
+	// en orden y ninguna queda fuera del tiempo simulado
+	for id, packet := range p.State.Packets {
+		prev := epoch
+		for _, tr := range packet.Transitions {
+			if tr.At.Before(prev) || tr.At.After(p.Clock.Now()) {
+				t.Errorf("%s: %v en %v, fuera de [%v, %v]", id, tr.Status, tr.At, prev, p.Clock.Now())
+			}
+			prev = tr.At
+		}
+	}
+
+	for _, d := range p.State.Devices {
+		if d.DisplayDistancia == 0 || d.DisplayNitidez == 0 {
+			t.Errorf("%s: dashboard sin actualizar: distancia=%v nitidez=%v",
+				d.ID, d.DisplayDistancia, d.DisplayNitidez)
+		}
+	}
+	for _, stage := range []*state.StageState{p.State.PythonAPI, p.State.RabbitMQ, p.State.WebsocketAPI} {
+		if stage.Utilisation() <= 0 {
+			t.Errorf("%s: utilización = 0 tras procesar paquetes", stage.Name)
+		}
+		if len(stage.InService) != 0 || len(stage.Queue) != 0 {
+			t.Errorf("%s: quedaron paquetes en la etapa", stage.Name)
+		}
+	}
+}
+
+func TestPacketLifecycleAPIError(t *testing.T) {
+	_, seen := runHeadless(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "caído", http.StatusServiceUnavailable)
+	}))
+
+	for id, history := range seen {
+		if last := history[len(history)-1]; last != state.Error {
+			t.Errorf("%s: estado final = %v, want Error", id, last)
+		}
+	}
+}
+
+func TestMultipleDevices(t *testing.T) {
+	cfg := testConfig()
+	cfg.Devices = Devices(2)
+	cfg.Devices[1].Sensors = []Sensor{MPU}
+	cfg.Devices[1].Tilt = 6
+
+	projects := make(chan int, 4)
+	p, seen := runHeadlessConfig(t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			IDProject int `json:"id_project"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		projects <- body.IDProject
+	}))
+	close(projects)
+
+	for _, id := range []string{"geova1/tfluna", "geova1/mpu", "geova1/imx", "geova2/mpu"} {
+		if last := seen[id]; len(last) == 0 || last[len(last)-1] != state.Done {
+			t.Errorf("%s: historial %v, want terminar en Done", id, last)
+		}
+	}
+	if len(seen) != 4 {
+		t.Errorf("paquetes = %d, want 4", len(seen))
+	}
+
+	count := map[int]int{}
+	for id := range projects {
+		count[id]++
+	}
+	if count[4] != 3 || count[5] != 1 {
+		t.Errorf("lecturas por proyecto = %v, want 3 del 4 y 1 del 5", count)
+	}
+
+	d1, d2 := p.State.Devices[0], p.State.Devices[1]
+	if d2.DisplayRoll != 6 || d2.DisplayDistancia != 0 {
+		t.Errorf("geova2: roll=%v distancia=%v, want 6 y sin TF-Luna", d2.DisplayRoll, d2.DisplayDistancia)
+	}
+	if d1.DisplayRoll != 0 || d1.DisplayDistancia == 0 {
+		t.Errorf("geova1: roll=%v distancia=%v, want 0 y con TF-Luna", d1.DisplayRoll, d1.DisplayDistancia)
+	}
+}
+
+func containsStatus(history []state.PacketStatus, s state.PacketStatus) bool {
+	for _, h := range history {
+		if h == s {
+			return true
+		}
+	}
+	return false
+}
+
+// benchPipeline crea n paquetes viajando hacia la API desde posiciones
+// distintas. Como SendingToAPI espera la respuesta HTTP, el costo por frame
+// se mantiene estable durante todo el benchmark.
+func benchPipeline(n int) *Pipeline {
+	visState := &state.VisualState{Packets: make(map[string]*state.PacketState, n)}
+	p := New(visState, clock.NewFake(epoch), testConfig())
+	visState.SimulacionIniciada = true
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("pkt-%d", i)
+		visState.Packets[id] = &state.PacketState{
+			ID:      id,
+			Active:  true,
+			X:       float64(-i % 5000),
+			Y:       float64(i % 650),
+			TargetX: p.Config.PythonAPI.Pos.X,
+			TargetY: p.Config.PythonAPI.Pos.Y,
+			Status:  state.SendingToAPI,
+		}
+	}
+	return p
+}
+
+func BenchmarkUpdatePacketFSM(b *testing.B) {
+	for _, n := range []int{1000, 5000, 20000} {
+		b.Run(fmt.Sprintf("packets=%d", n), func(b *testing.B) {
+			p := benchPipeline(n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p.updatePacketFSM(frame)
+			}
+		})
+	}
+}
+
+// BenchmarkPacketLifecycle mide el recorrido completo de n paquetes que ya
+// llegaron a la API, incluyendo colas y servicio en las tres etapas.
+func BenchmarkPacketLifecycle(b *testing.B) {
+	for _, n := range []int{1000, 5000} {
+		b.Run(fmt.Sprintf("packets=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				p := benchPipeline(n)
+				for _, packet := range p.State.Packets {
+					packet.Status = state.ArrivedAtAPI
+				}
+				b.StartTimer()
+
+				for p.State.SimulacionIniciada {
+					p.updatePacketFSM(time.Second)
+				}
+			}
+		})
+	}
+}
+
+// TestStreamFollowsSimulatedTime comprueba que el streaming no genera
+// muestras en pausa y sigue la escala de tiempo. Las lecturas quedan en un
+// lote que nunca se llena, así que no sale nada a la red.
+func TestStreamFollowsSimulatedTime(t *testing.T) {
+	p, clk := newTestPipeline()
+	p.Config.Batch = BatchConfig{Size: 1000}
+	p.StartStream(MPU, 100*time.Millisecond)
+
+	steps := []struct {
+		advance time.Duration
+		scale   float64
+		want    int
+	}{
+		{time.Second, 0, 1}, // En pausa solo está la muestra inicial
+		{200 * time.Millisecond, 1, 3},
+		{100 * time.Millisecond, 2, 5},
+		{50 * time.Millisecond, 1, 5},
+		{60 * time.Millisecond, 1, 6},
+	}
+	for i, s := range steps {
+		clk.Advance(s.advance)
+		p.Tick(s.scale)
+		p.State.Mutex.Lock()
+		got := p.stream.n
+		p.State.Mutex.Unlock()
+		if got != s.want {
+			t.Errorf("paso %d: %d muestras, want %d", i, got, s.want)
+		}
+	}
+}
+
+func TestStreamSampleReadsCurrentTilt(t *testing.T) {
+	rolls := make(chan float64, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data simulation.MPUData
+		json.NewDecoder(r.Body).Decode(&data)
+		rolls <- data.Roll
+	}))
+	defer srv.Close()
+
+	p, _ := newTestPipeline()
+	p.Config.MPUURL = srv.URL
+	sensor := &simulation.MPUSensor{}
+
+	for n, tilt := range []float64{4, 9} {
+		p.State.Mutex.Lock()
+		p.State.Devices[0].Tilt = tilt
+		p.State.Mutex.Unlock()
+
+		p.streamSample(0, MPU, sensor, n+1, nil, epoch)
+		select {
+		case got := <-rolls:
+			if got != tilt {
+				t.Errorf("muestra %d: roll = %v, want %v", n+1, got, tilt)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("muestra %d no llegó al servidor", n+1)
+		}
+	}
+
+	// Espera a que los workers terminen antes de cerrar el servidor.
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		p.State.Mutex.Lock()
+		done := p.State.Packets["geova1/mpu-1"].Status == state.ArrivedAtAPI &&
+			p.State.Packets["geova1/mpu-2"].Status == state.ArrivedAtAPI
+		p.State.Mutex.Unlock()
+		if done {
+			return
+		}
+	}
+	t.Fatal("los workers no terminaron")
+}
@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"geova-simulation/state"
+	"os"
+	"time"
+)
+
+// MaxStageCapacity acota los slots de una etapa; con más no entran en la
+// escena los paquetes en servicio.
+const MaxStageCapacity = 8
+
+// stageSpec es una etapa en el archivo de -stages. Las duraciones van como
+// en Go ("500ms", "1.5s").
+type stageSpec struct {
+	Capacity int    `json:"capacity"`
+	Service  string `json:"service"`
+	Mean     string `json:"mean"`
+	Spread   string `json:"spread"` // Solo uniform
+}
+
+// LoadStages aplica el archivo JSON en path sobre las etapas de cfg. Las
+// etapas y los campos que no aparecen conservan su valor:
+//
+//	{
+//	  "python_api": {"capacity": 2, "service": "uniform", "mean": "400ms", "spread": "100ms"},
+//	  "rabbitmq":   {"service": "exponential", "mean": "300ms"},
+//	  "websocket":  {"capacity": 1, "service": "fixed", "mean": "200ms"}
+//	}
+func LoadStages(path string, cfg Config) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	stages := map[string]*StageConfig{
+		"python_api": &cfg.PythonAPI,
+		"rabbitmq":   &cfg.RabbitMQ,
+		"websocket":  &cfg.WebsocketAPI,
+	}
+	specs := make(map[string]*stageSpec, len(stages))
+	for name, stage := range stages {
+		specs[name] = &stageSpec{
+			Capacity: stage.Capacity,
+			Service:  stage.Service.Dist.String(),
+			Mean:     stage.Service.Mean.String(),
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var file map[string]json.RawMessage
+	if err := dec.Decode(&file); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	for name, raw := range file {
+		spec, ok := specs[name]
+		if !ok {
+			return cfg, fmt.Errorf("%s: etapa desconocida '%s' (opciones: python_api, rabbitmq, websocket)", path, name)
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(spec); err != nil {
+			return cfg, fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+		if err := spec.apply(stages[name]); err != nil {
+			return cfg, fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+	}
+	return cfg, nil
+}
+
+// apply valida la etapa del archivo y la copia en stage.
+func (s *stageSpec) apply(stage *StageConfig) error {
+	dist, err := state.ParseServiceDist(s.Service)
+	if err != nil {
+		return err
+	}
+	mean, err := time.ParseDuration(s.Mean)
+	if err != nil {
+		return fmt.Errorf("mean: %w", err)
+	}
+	var spread time.Duration
+	switch {
+	case s.Spread != "":
+		if dist != state.Uniform {
+			return fmt.Errorf("spread solo aplica a uniform")
+		}
+		if spread, err = time.ParseDuration(s.Spread); err != nil {
+			return fmt.Errorf("spread: %w", err)
+		}
+	case dist == stage.Service.Dist:
+		spread = stage.Service.Spread
+	}
+	stage.Capacity = s.Capacity
+	stage.Service = state.ServiceTime{Dist: dist, Mean: mean, Spread: spread}
+	return stage.Check()
+}
+
+// Check valida la capacidad y el tiempo de servicio de la etapa.
+func (c StageConfig) Check() error {
+	if c.Capacity < 1 || c.Capacity > MaxStageCapacity {
+		return fmt.Errorf("capacity debe estar entre 1 y %d", MaxStageCapacity)
+	}
+	if c.Service.Mean <= 0 {
+		return fmt.Errorf("mean debe ser mayor que 0")
+	}
+	if c.Service.Spread < 0 || c.Service.Spread > c.Service.Mean {
+		return fmt.Errorf("spread debe estar entre 0 y mean (%v)", c.Service.Mean)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package pipeline
+
+import (
+	"geova-simulation/state"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadStages(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "stages.json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := LoadStages(write(`{
+		"python_api": {"mean": "300ms"},
+		"rabbitmq":   {"capacity": 4, "service": "uniform", "mean": "1s", "spread": "250ms"}
+	}`), DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := DefaultConfig()
+	if want := (state.ServiceTime{Dist: state.Uniform, Mean: 300 * time.Millisecond, Spread: def.PythonAPI.Service.Spread}); cfg.PythonAPI.Service != want || cfg.PythonAPI.Capacity != 1 {
+		t.Errorf("python_api = %d %+v, want 1 %+v", cfg.PythonAPI.Capacity, cfg.PythonAPI.Service, want)
+	}
+	if want := (state.ServiceTime{Dist: state.Uniform, Mean: time.Second, Spread: 250 * time.Millisecond}); cfg.RabbitMQ.Service != want || cfg.RabbitMQ.Capacity != 4 {
+		t.Errorf("rabbitmq = %d %+v, want 4 %+v", cfg.RabbitMQ.Capacity, cfg.RabbitMQ.Service, want)
+	}
+	if cfg.WebsocketAPI != def.WebsocketAPI {
+		t.Errorf("websocket = %+v, want el valor por defecto", cfg.WebsocketAPI)
+	}
+
+	for src, want := range map[string]string{
+		`{"kafka": {"capacity": 1}}`:                           "etapa desconocida 'kafka'",
+		`{"rabbitmq": {"capacity": 0}}`:                        "capacity debe estar entre 1 y 8",
+		`{"rabbitmq": {"capacity": 99}}`:                       "capacity debe estar entre 1 y 8",
+		`{"rabbitmq": {"service": "normal"}}`:                  "distribución desconocida 'normal'",
+		`{"rabbitmq": {"mean": "0s"}}`:                         "mean debe ser mayor que 0",
+		`{"rabbitmq": {"mean": "medio segundo"}}`:              "mean: time: invalid duration",
+		`{"rabbitmq": {"spread": "100ms"}}`:                    "spread solo aplica a uniform",
+		`{"python_api": {"mean": "100ms", "spread": "200ms"}}`: "spread debe estar entre 0 y mean",
+		`{"python_api": {"capacidad": 2}}`:                     `unknown field "capacidad"`,
+	} {
+		if _, err := LoadStages(write(src), DefaultConfig()); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadStages(%s) = %v, want error con %q", src, err, want)
+		}
+	}
+}
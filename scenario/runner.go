@@ -0,0 +1,195 @@
+package scenario
+
+import (
+	"fmt"
+	"geova-simulation/expect"
+	"geova-simulation/pipeline"
+	"log"
+	"strings"
+	"time"
+)
+
+// Runner ejecuta un escenario sobre un pipeline. Quien maneja el game loop
+// llama a Advance con el tiempo simulado de cada frame.
+type Runner struct {
+	Scenario *Scenario
+
+	pipe    *pipeline.Pipeline
+	elapsed time.Duration
+	next    int
+
+	rec *expect.Recorder
+}
+
+// NewRunner valida que los dispositivos que nombra el escenario existan en
+// el pipeline.
+func NewRunner(sc *Scenario, pipe *pipeline.Pipeline) (*Runner, error) {
+	for _, step := range sc.Steps {
+		if tilt, ok := step.Action.(tiltAction); ok && tilt.device != "" && pipe.State.Device(tilt.device) == nil {
+			return nil, fmt.Errorf("línea %d: dispositivo desconocido '%s'", step.Line, tilt.device)
+		}
+	}
+	return &Runner{
+		Scenario: sc,
+		pipe:     pipe,
+		rec:      expect.NewRecorder(),
+	}, nil
+}
+
+// Advance avanza el guion dt y ejecuta los pasos que vencieron.
+func (r *Runner) Advance(dt time.Duration) {
+	r.elapsed += dt
+	for r.next < len(r.Scenario.Steps) && r.Scenario.Steps[r.next].At <= r.elapsed {
+		step := r.Scenario.Steps[r.next]
+		r.next++
+		log.Printf("🎬 [%s] t=%s: %s", r.Scenario.Name, step.At, step.Action)
+		if err := step.Action.run(r); err != nil {
+			log.Printf("⚠️  [%s] línea %d: %v", r.Scenario.Name, step.Line, err)
+		}
+	}
+	r.rec.Observe(r.pipe.State)
+}
+
+// Elapsed es el tiempo de guion transcurrido.
+func (r *Runner) Elapsed() time.Duration {
+	return r.elapsed
+}
+
+// Progress retorna cuántos pasos se ejecutaron y cuántos hay.
+func (r *Runner) Progress() (done, total int) {
+	return r.next, len(r.Scenario.Steps)
+}
+
+// Finished indica que ya se ejecutaron todos los pasos y no quedan paquetes
+// en vuelo.
+func (r *Runner) Finished() bool {
+	if r.next < len(r.Scenario.Steps) {
+		return false
+	}
+	r.pipe.State.Mutex.Lock()
+	defer r.pipe.State.Mutex.Unlock()
+	return !r.pipe.State.SimulacionIniciada
+}
+
+// Results evalúa las expectativas del escenario con los paquetes vistos
+// hasta ahora.
+func (r *Runner) Results() []expect.Result {
+	return expect.Evaluate(r.Scenario.Expectations, r.rec)
+}
+
+// --- Acciones ---
+
+type tiltAction struct {
+	device      string // Vacío: todos los dispositivos
+	roll, pitch float64
+	setPitch    bool
+}
+
+func (a tiltAction) run(r *Runner) error {
+	s := r.pipe.State
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+	for _, d := range s.Devices {
+		if a.device != "" && d.ID != a.device {
+			continue
+		}
+		d.Tilt = a.roll
+		if a.setPitch {
+			d.Pitch = a.pitch
+		}
+	}
+	return nil
+}
+
+func (a tiltAction) String() string {
+	s := fmt.Sprintf("tilt %g", a.roll)
+	if a.device != "" {
+		s = fmt.Sprintf("tilt %s %g", a.device, a.roll)
+	}
+	if a.setPitch {
+		s += fmt.Sprintf(" %g", a.pitch)
+	}
+	return s
+}
+
+type startAction struct{}
+
+func (startAction) run(r *Runner) error {
+	r.pipe.State.Mutex.Lock()
+	busy := r.pipe.State.SimulacionIniciada
+	r.pipe.State.Mutex.Unlock()
+	if busy {
+		return fmt.Errorf("start: ya hay una simulación en curso")
+	}
+	r.pipe.Start()
+	return nil
+}
+
+func (startAction) String() string { return "start" }
+
+type streamAction struct {
+	sensor   pipeline.Sensor
+	interval time.Duration
+}
+
+func (a streamAction) run(r *Runner) error {
+	r.pipe.State.Mutex.Lock()
+	busy := r.pipe.State.SimulacionIniciada
+	r.pipe.State.Mutex.Unlock()
+	if busy {
+		return fmt.Errorf("stream: ya hay una simulación en curso")
+	}
+	r.pipe.StartStream(a.sensor, a.interval)
+	return nil
+}
+
+func (a streamAction) String() string {
+	return fmt.Sprintf("stream %s cada %s", a.sensor, a.interval)
+}
+
+type stopAction struct{}
+
+func (stopAction) run(r *Runner) error {
+	r.pipe.StopStreaming()
+	return nil
+}
+
+func (stopAction) String() string { return "stop" }
+
+type failAction struct {
+	target string
+	status int
+}
+
+func (a failAction) run(r *Runner) error {
+	r.pipe.Sender.Faults.Inject(r.urlPrefix(a.target), a.status)
+	return nil
+}
+
+func (a failAction) String() string { return fmt.Sprintf("fail %s %d", a.target, a.status) }
+
+type recoverAction struct {
+	target string // Vacío: todas las fallas
+}
+
+func (a recoverAction) run(r *Runner) error {
+	if a.target == "" {
+		r.pipe.Sender.Faults.Clear()
+	} else {
+		r.pipe.Sender.Faults.Recover(r.urlPrefix(a.target))
+	}
+	return nil
+}
+
+func (a recoverAction) String() string {
+	return strings.TrimSpace("recover " + a.target)
+}
+
+// urlPrefix traduce el objetivo de una falla al prefijo de URL del
+// inyector: "api" cubre todos los endpoints y un sensor solo el suyo.
+func (r *Runner) urlPrefix(target string) string {
+	if target == "api" {
+		return ""
+	}
+	return r.pipe.Config.URL(pipeline.Sensor(target))
+}
@@ -0,0 +1,256 @@
+// Package scenario lee guiones de demostración y los ejecuta contra la
+// simulación, tanto en la UI como en headless. Un guion es un archivo de
+// texto con una instrucción por línea:
+//
+//	# La API se cae en medio del streaming
+//	at 0s   tilt 5
+//	at 2s   stream tfluna 5hz
+//	at 10s  fail api 503 for 5s
+//	at 20s  stop
+//	expect errors >= 1
+//	expect done >= 40
+//	expect p95 <= 3s
+//
+// Las expectativas tienen la sintaxis del paquete expect.
+// Los tiempos son de simulación: se detienen en pausa y siguen la escala de
+// tiempo de la UI.
+package scenario
+
+import (
+	"bufio"
+	"fmt"
+	"geova-simulation/expect"
+	"geova-simulation/pipeline"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scenario es un guion ya interpretado; los pasos están ordenados por tiempo.
+type Scenario struct {
+	Name         string
+	Steps        []Step
+	Expectations []expect.Expectation
+}
+
+// Step es una acción que se ejecuta cuando el guion llega a At.
+type Step struct {
+	At     time.Duration
+	Line   int
+	Action Action
+}
+
+// Action es una instrucción del guion: tilt, start, stream, stop, fail o
+// recover.
+type Action interface {
+	run(r *Runner) error
+	String() string
+}
+
+// Load lee el guion en path; el nombre del escenario es el del archivo.
+func Load(path string) (*Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sc, err := Parse(f, filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return sc, nil
+}
+
+func Parse(r io.Reader, name string) (*Scenario, error) {
+	sc := &Scenario{Name: name}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var err error
+		switch fields[0] {
+		case "at":
+			err = sc.parseStep(line, fields[1:])
+		case "expect":
+			var e expect.Expectation
+			if e, err = expect.ParseFields(fields[1:]); err == nil {
+				sc.Expectations = append(sc.Expectations, e)
+			}
+		default:
+			err = fmt.Errorf("se esperaba 'at' o 'expect', no '%s'", fields[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("línea %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(sc.Steps, func(i, j int) bool { return sc.Steps[i].At < sc.Steps[j].At })
+	return sc, nil
+}
+
+// parseStep interpreta "at <tiempo> <acción> <args...>". Un "fail ... for
+// <duración>" agrega también el recover correspondiente.
+func (sc *Scenario) parseStep(line int, fields []string) error {
+	if len(fields) < 2 {
+		return fmt.Errorf("falta la acción: at <tiempo> <acción>")
+	}
+	at, err := parseDuration(strings.TrimPrefix(fields[0], "t="))
+	if err != nil {
+		return err
+	}
+	name, args := fields[1], fields[2:]
+
+	var action Action
+	switch name {
+	case "tilt":
+		action, err = parseTilt(args)
+	case "start":
+		action, err = startAction{}, wantArgs(args, 0)
+	case "stop":
+		action, err = stopAction{}, wantArgs(args, 0)
+	case "stream":
+		action, err = parseStream(args)
+	case "fail":
+		var fail failAction
+		var recoverAfter time.Duration
+		if fail, recoverAfter, err = parseFail(args); err == nil && recoverAfter > 0 {
+			sc.Steps = append(sc.Steps, Step{At: at + recoverAfter, Line: line, Action: recoverAction{target: fail.target}})
+		}
+		action = fail
+	case "recover":
+		action, err = parseRecover(args)
+	default:
+		return fmt.Errorf("acción desconocida '%s'", name)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	sc.Steps = append(sc.Steps, Step{At: at, Line: line, Action: action})
+	return nil
+}
+
+func wantArgs(args []string, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("se esperaban %d argumentos, hay %d", n, len(args))
+	}
+	return nil
+}
+
+// parseDuration acepta duraciones de Go ("2s", "1m30s") y el 0 sin unidad.
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("tiempo inválido '%s'", s)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("tiempo negativo '%s'", s)
+	}
+	return d, nil
+}
+
+// parseTilt: tilt [dispositivo] <roll> [pitch]. Sin dispositivo se inclinan
+// todos.
+func parseTilt(args []string) (Action, error) {
+	var a tiltAction
+	if len(args) > 0 {
+		if _, err := strconv.ParseFloat(args[0], 64); err != nil {
+			a.device, args = args[0], args[1:]
+		}
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("uso: tilt [dispositivo] <roll> [pitch]")
+	}
+	var err error
+	if a.roll, err = strconv.ParseFloat(args[0], 64); err != nil {
+		return nil, fmt.Errorf("roll inválido '%s'", args[0])
+	}
+	if len(args) == 2 {
+		if a.pitch, err = strconv.ParseFloat(args[1], 64); err != nil {
+			return nil, fmt.Errorf("pitch inválido '%s'", args[1])
+		}
+		a.setPitch = true
+	}
+	return a, nil
+}
+
+// parseStream: stream <sensor> <frecuencia>, con la frecuencia en Hz ("5hz")
+// o como intervalo ("200ms").
+func parseStream(args []string) (Action, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("uso: stream <sensor> <frecuencia>")
+	}
+	sensor, err := pipeline.ParseSensor(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var interval time.Duration
+	if hz, ok := strings.CutSuffix(strings.ToLower(args[1]), "hz"); ok {
+		f, err := strconv.ParseFloat(hz, 64)
+		if err != nil || f <= 0 {
+			return nil, fmt.Errorf("frecuencia inválida '%s'", args[1])
+		}
+		interval = time.Duration(float64(time.Second) / f)
+	} else if interval, err = time.ParseDuration(args[1]); err != nil || interval <= 0 {
+		return nil, fmt.Errorf("frecuencia inválida '%s'", args[1])
+	}
+	return streamAction{sensor: sensor, interval: interval}, nil
+}
+
+// parseFail: fail <api|sensor> <código> [for <duración>].
+func parseFail(args []string) (failAction, time.Duration, error) {
+	if len(args) != 2 && len(args) != 4 {
+		return failAction{}, 0, fmt.Errorf("uso: fail <api|sensor> <código> [for <duración>]")
+	}
+	if err := checkTarget(args[0]); err != nil {
+		return failAction{}, 0, err
+	}
+	status, err := strconv.Atoi(args[1])
+	if err != nil || status < 400 || status > 599 {
+		return failAction{}, 0, fmt.Errorf("código HTTP de error inválido '%s'", args[1])
+	}
+
+	var d time.Duration
+	if len(args) == 4 {
+		if args[2] != "for" {
+			return failAction{}, 0, fmt.Errorf("se esperaba 'for', no '%s'", args[2])
+		}
+		if d, err = parseDuration(args[3]); err != nil {
+			return failAction{}, 0, err
+		}
+	}
+	return failAction{target: args[0], status: status}, d, nil
+}
+
+// parseRecover: recover [api|sensor]. Sin objetivo quita todas las fallas.
+func parseRecover(args []string) (Action, error) {
+	switch len(args) {
+	case 0:
+		return recoverAction{}, nil
+	case 1:
+		return recoverAction{target: args[0]}, checkTarget(args[0])
+	}
+	return nil, fmt.Errorf("uso: recover [api|sensor]")
+}
+
+// checkTarget valida el objetivo de una falla: "api" (todos los endpoints)
+// o el nombre de un sensor (solo su endpoint).
+func checkTarget(target string) error {
+	if target == "api" {
+		return nil
+	}
+	_, err := pipeline.ParseSensor(target)
+	return err
+}
@@ -0,0 +1,136 @@
+package scenario
+
+import (
+	"geova-simulation/clock"
+	"geova-simulation/pipeline"
+	"geova-simulation/state"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	sc, err := Parse(strings.NewReader(`
+# demo
+at t=0     tilt 5
+at 2s      stream tfluna 5hz
+at 10s     fail api 503 for 5s
+at 20s     stop
+at 1s      tilt geova2 -3 1.5
+expect all done
+expect errors >= 1
+`), "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		at     time.Duration
+		action string
+	}{
+		{0, "tilt 5"},
+		{time.Second, "tilt geova2 -3 1.5"},
+		{2 * time.Second, "stream tfluna cada 200ms"},
+		{10 * time.Second, "fail api 503"},
+		{15 * time.Second, "recover api"},
+		{20 * time.Second, "stop"},
+	}
+	if len(sc.Steps) != len(want) {
+		t.Fatalf("pasos = %d, want %d: %+v", len(sc.Steps), len(want), sc.Steps)
+	}
+	for i, w := range want {
+		if got := sc.Steps[i]; got.At != w.at || got.Action.String() != w.action {
+			t.Errorf("paso %d = %s %q, want %s %q", i, got.At, got.Action, w.at, w.action)
+		}
+	}
+	if len(sc.Expectations) != 2 || sc.Expectations[1].String() != "errors >= 1" {
+		t.Errorf("expectativas = %v", sc.Expectations)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"tilt 5", "línea 1: se esperaba 'at' o 'expect'"},
+		{"at 0s jump", "línea 1: acción desconocida 'jump'"},
+		{"\nat dos tilt 5", "línea 2: tiempo inválido 'dos'"},
+		{"at 0s stream lidar 5hz", "sensor desconocido 'lidar'"},
+		{"at 0s stream mpu 0hz", "frecuencia inválida '0hz'"},
+		{"at 0s fail api 200", "código HTTP de error inválido '200'"},
+		{"at 0s fail api 503 during 5s", "se esperaba 'for'"},
+		{"expect done ~ 3", "operador desconocido '~'"},
+		{"expect p95 <= rápido", "duración inválida 'rápido'"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.src), "x")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) = %v, want error con %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+// TestRunnerWithFault corre un start con la API caída para el MPU: los
+// otros dos sensores llegan a Done y el MPU termina en Error.
+func TestRunnerWithFault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	cfg := pipeline.DefaultConfig()
+	cfg.TFLunaURL = srv.URL + "/tfluna/sensor"
+	cfg.MPUURL = srv.URL + "/mpu/sensor"
+	cfg.IMXURL = srv.URL + "/imx477/sensor"
+	clk := clock.NewFake(epoch)
+	pipe := pipeline.New(&state.VisualState{Packets: make(map[string]*state.PacketState)}, clk, cfg)
+
+	sc, err := Parse(strings.NewReader(`
+at 0s   tilt 4
+at 0s   fail mpu 503
+at 1s   start
+expect packets == 3
+expect errors == 1
+expect all done
+`), "falla")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRunner(sc, pipe)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const frame = time.Second / 60
+	deadline := time.Now().Add(10 * time.Second)
+	for !r.Finished() {
+		if time.Now().After(deadline) {
+			t.Fatal("el escenario no terminó")
+		}
+		clk.Advance(frame)
+		r.Advance(pipe.Tick(1))
+		time.Sleep(time.Millisecond)
+	}
+
+	if pipe.State.Devices[0].Tilt != 4 {
+		t.Errorf("tilt = %v, want 4", pipe.State.Devices[0].Tilt)
+	}
+	results := r.Results()
+	for i, want := range []bool{true, true, false} {
+		if results[i].Passed != want {
+			t.Errorf("%s: passed = %v, want %v (%s)", results[i].Name, results[i].Passed, want, results[i].Got)
+		}
+	}
+}
+
+func TestNewRunnerUnknownDevice(t *testing.T) {
+	sc, _ := Parse(strings.NewReader("at 0s tilt geova9 3"), "x")
+	pipe := pipeline.New(&state.VisualState{}, clock.NewFake(epoch), pipeline.DefaultConfig())
+	if _, err := NewRunner(sc, pipe); err == nil || !strings.Contains(err.Error(), "geova9") {
+		t.Errorf("err = %v, want dispositivo desconocido", err)
+	}
+}
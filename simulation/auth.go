@@ -0,0 +1,332 @@
+package simulation
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"geova-simulation/clock"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator agrega credenciales a una petición. body es el cuerpo que se
+// va a enviar (ya comprimido), para los esquemas que lo firman.
+type Authenticator interface {
+	Authorize(req *http.Request, body []byte) error
+	String() string
+}
+
+// AuthRule aplica Auth a las peticiones cuya URL empieza con Prefix; con
+// Prefix vacío, a todas.
+type AuthRule struct {
+	Prefix string
+	Auth   Authenticator
+}
+
+// String describe la regla sin sus secretos, para el log de arranque.
+func (r AuthRule) String() string {
+	if r.Prefix == "" {
+		return r.Auth.String() + " en todos los endpoints"
+	}
+	return r.Auth.String() + " en " + r.Prefix + "*"
+}
+
+// Authorizer es un http.RoundTripper que autentica cada petición con la
+// regla más específica (prefijo más largo) y la pasa a Next. En el Sender va
+// después de la compresión, así que firma el cuerpo tal como sale a la red.
+// Si la API responde 401 con un token de OAuth2 cacheado, lo descarta y
+// reintenta una vez con uno nuevo.
+type Authorizer struct {
+	Next http.RoundTripper
+
+	mu    sync.Mutex
+	rules []AuthRule
+}
+
+// SetRules reemplaza las reglas.
+func (a *Authorizer) SetRules(rules []AuthRule) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rules = rules
+}
+
+// Rules retorna una copia de las reglas, para mostrarlas al arrancar.
+func (a *Authorizer) Rules() []AuthRule {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]AuthRule(nil), a.rules...)
+}
+
+func (a *Authorizer) match(url string) (Authenticator, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var auth Authenticator
+	best := -1
+	for _, r := range a.rules {
+		if strings.HasPrefix(url, r.Prefix) && len(r.Prefix) > best {
+			auth, best = r.Auth, len(r.Prefix)
+		}
+	}
+	return auth, best >= 0
+}
+
+func (a *Authorizer) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := a.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	auth, ok := a.match(req.URL.String())
+	if !ok {
+		return next.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	var authorization string // El header que se envió, para saber qué token se rechazó
+	send := func() (*http.Response, error) {
+		// Un RoundTripper no puede modificar la petición que recibe
+		out := req.Clone(req.Context())
+		if req.Body != nil {
+			out.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		if err := auth.Authorize(out, body); err != nil {
+			return nil, fmt.Errorf("auth %s: %w", auth, err)
+		}
+		authorization = out.Header.Get("Authorization")
+		return next.RoundTrip(out)
+	}
+
+	resp, err := send()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	cached, ok := auth.(*OAuth2)
+	if !ok {
+		return resp, nil
+	}
+	cached.Invalidate(strings.TrimPrefix(authorization, "Bearer "))
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return send()
+}
+
+// Bearer manda un token fijo en el header Authorization.
+type Bearer struct {
+	Token string
+}
+
+func (b *Bearer) Authorize(req *http.Request, _ []byte) error {
+	req.Header.Set("Authorization", "Bearer "+b.Token)
+	return nil
+}
+
+func (b *Bearer) String() string { return "bearer" }
+
+// APIKey manda una clave fija en Header (X-API-Key por defecto).
+type APIKey struct {
+	Header string
+	Key    string
+}
+
+func (k *APIKey) Authorize(req *http.Request, _ []byte) error {
+	header := k.Header
+	if header == "" {
+		header = "X-API-Key"
+	}
+	req.Header.Set(header, k.Key)
+	return nil
+}
+
+func (k *APIKey) String() string { return "api_key" }
+
+// HMAC firma cada petición con HMAC-SHA256 sobre
+//
+//	<timestamp>\n<método>\n<ruta>\n<cuerpo>
+//
+// y manda el timestamp (segundos Unix de Clock) en X-Geova-Timestamp y la
+// firma en hexadecimal en X-Geova-Signature. El backend rechaza firmas
+// viejas para evitar que se repitan peticiones capturadas.
+type HMAC struct {
+	KeyID  string // Opcional; va en X-Geova-Key-Id
+	Secret string
+	Clock  clock.Clock // nil: el reloj del sistema
+}
+
+const (
+	TimestampHeader = "X-Geova-Timestamp"
+	SignatureHeader = "X-Geova-Signature"
+	KeyIDHeader     = "X-Geova-Key-Id"
+)
+
+func (h *HMAC) Authorize(req *http.Request, body []byte) error {
+	ts := strconv.FormatInt(readClock(h.Clock).Unix(), 10)
+	req.Header.Set(TimestampHeader, ts)
+	req.Header.Set(SignatureHeader, Sign(h.Secret, ts, req.Method, req.URL.Path, body))
+	if h.KeyID != "" {
+		req.Header.Set(KeyIDHeader, h.KeyID)
+	}
+	return nil
+}
+
+func (h *HMAC) String() string { return "hmac" }
+
+// readClock lee clk, o el reloj del sistema si es nil.
+func readClock(clk clock.Clock) time.Time {
+	if clk == nil {
+		return time.Now()
+	}
+	return clk.Now()
+}
+
+// Sign calcula la firma de HMAC; el backend la recalcula igual para
+// verificarla.
+func Sign(secret, timestamp, method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n", timestamp, method, path)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AuthEndpoint es una entrada del archivo de -auth. Los valores pueden
+// referirse a variables de entorno (${GEOVA_TOKEN}), para no guardar
+// secretos en el archivo:
+//
+//	{"endpoints": [
+//	  {"url": "", "type": "oauth2", "token_url": "https://auth.geova.io/token",
+//	   "client_id": "simulador", "client_secret": "${GEOVA_CLIENT_SECRET}"},
+//	  {"url": "http://localhost:8000/imx477", "type": "hmac", "secret": "${GEOVA_HMAC_SECRET}"}
+//	]}
+type AuthEndpoint struct {
+	URL          string   `json:"url"` // Prefijo; vacío: todos los endpoints
+	Type         string   `json:"type"`
+	Token        string   `json:"token,omitempty"`
+	Header       string   `json:"header,omitempty"`
+	Key          string   `json:"key,omitempty"`
+	KeyID        string   `json:"key_id,omitempty"`
+	Secret       string   `json:"secret,omitempty"`
+	TokenURL     string   `json:"token_url,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+// authenticator valida la entrada y arma su Authenticator; los que dependen
+// de la hora la leen de clk.
+func (e AuthEndpoint) authenticator(clk clock.Clock) (Authenticator, error) {
+	switch e.Type {
+	case "bearer":
+		return &Bearer{Token: e.Token}, e.require("token", e.Token)
+	case "api_key":
+		return &APIKey{Header: e.Header, Key: e.Key}, e.require("key", e.Key)
+	case "hmac":
+		return &HMAC{KeyID: e.KeyID, Secret: e.Secret, Clock: clk}, e.require("secret", e.Secret)
+	case "oauth2":
+		err := e.require("token_url", e.TokenURL, "client_id", e.ClientID, "client_secret", e.ClientSecret)
+		return &OAuth2{TokenURL: e.TokenURL, ClientID: e.ClientID, ClientSecret: e.ClientSecret, Scopes: e.Scopes, Clock: clk}, err
+	}
+	return nil, fmt.Errorf("tipo de auth desconocido '%s' (opciones: bearer, api_key, hmac, oauth2)", e.Type)
+}
+
+// require recibe pares nombre, valor y falla con el primero vacío.
+func (e AuthEndpoint) require(fields ...string) error {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i+1] == "" {
+			return fmt.Errorf("%s: falta \"%s\"", e.Type, fields[i])
+		}
+	}
+	return nil
+}
+
+// LoadAuthConfig lee el archivo de -auth y arma sus reglas.
+func LoadAuthConfig(path string, clk clock.Clock) ([]AuthRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg struct {
+		Endpoints []AuthEndpoint `json:"endpoints"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	rules := make([]AuthRule, 0, len(cfg.Endpoints))
+	for i, e := range cfg.Endpoints {
+		e = e.expand()
+		auth, err := e.authenticator(clk)
+		if err != nil {
+			return nil, fmt.Errorf("%s: endpoint %d: %w", path, i+1, err)
+		}
+		rules = append(rules, AuthRule{Prefix: e.URL, Auth: auth})
+	}
+	return rules, nil
+}
+
+// expand reemplaza las variables de entorno de los valores.
+func (e AuthEndpoint) expand() AuthEndpoint {
+	for _, s := range []*string{&e.URL, &e.Token, &e.Header, &e.Key, &e.KeyID, &e.Secret, &e.TokenURL, &e.ClientID, &e.ClientSecret} {
+		*s = os.ExpandEnv(*s)
+	}
+	scopes := make([]string, len(e.Scopes))
+	for i, scope := range e.Scopes {
+		scopes[i] = os.ExpandEnv(scope)
+	}
+	e.Scopes = scopes
+	return e
+}
+
+// AuthFromEnv arma una regla con las variables GEOVA_AUTH_*: GEOVA_AUTH_TYPE
+// elige el tipo y el resto lleva los campos de AuthEndpoint en mayúsculas
+// (GEOVA_AUTH_TOKEN, GEOVA_AUTH_CLIENT_SECRET, GEOVA_AUTH_SCOPES separados
+// por comas...). Sin GEOVA_AUTH_TYPE no hay auth.
+func AuthFromEnv(getenv func(string) string, clk clock.Clock) ([]AuthRule, error) {
+	if getenv("GEOVA_AUTH_TYPE") == "" {
+		return nil, nil
+	}
+	e := AuthEndpoint{
+		URL:          getenv("GEOVA_AUTH_URL"),
+		Type:         getenv("GEOVA_AUTH_TYPE"),
+		Token:        getenv("GEOVA_AUTH_TOKEN"),
+		Header:       getenv("GEOVA_AUTH_HEADER"),
+		Key:          getenv("GEOVA_AUTH_KEY"),
+		KeyID:        getenv("GEOVA_AUTH_KEY_ID"),
+		Secret:       getenv("GEOVA_AUTH_SECRET"),
+		TokenURL:     getenv("GEOVA_AUTH_TOKEN_URL"),
+		ClientID:     getenv("GEOVA_AUTH_CLIENT_ID"),
+		ClientSecret: getenv("GEOVA_AUTH_CLIENT_SECRET"),
+	}
+	if scopes := getenv("GEOVA_AUTH_SCOPES"); scopes != "" {
+		e.Scopes = strings.Split(scopes, ",")
+	}
+	auth, err := e.authenticator(clk)
+	if err != nil {
+		return nil, fmt.Errorf("GEOVA_AUTH_*: %w", err)
+	}
+	return []AuthRule{{Prefix: e.URL, Auth: auth}}, nil
+}
+
+// LoadAuth carga las reglas de auth desde path o, si está vacío, desde las
+// variables de entorno.
+func LoadAuth(path string, clk clock.Clock) ([]AuthRule, error) {
+	if path != "" {
+		return LoadAuthConfig(path, clk)
+	}
+	return AuthFromEnv(os.Getenv, clk)
+}
@@ -0,0 +1,187 @@
+package simulation
+
+import (
+	"fmt"
+	"geova-simulation/clock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func roundTrip(t *testing.T, rt http.RoundTripper, url, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestAuthorizer(t *testing.T) {
+	fake := clock.NewFake(epoch)
+	var got http.Header
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		got, body = r.Header, string(data)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	a := &Authorizer{}
+	a.SetRules([]AuthRule{
+		{Prefix: "", Auth: &Bearer{Token: "t0k3n"}},
+		{Prefix: srv.URL + "/mpu", Auth: &APIKey{Key: "k3y"}},
+		{Prefix: srv.URL + "/imx477", Auth: &HMAC{KeyID: "sim", Secret: "s3cr3t", Clock: fake}},
+	})
+
+	roundTrip(t, a, srv.URL+"/tfluna/sensor", "{}")
+	if h := got.Get("Authorization"); h != "Bearer t0k3n" {
+		t.Errorf("tfluna: Authorization = %q", h)
+	}
+
+	roundTrip(t, a, srv.URL+"/mpu/sensor", "{}")
+	if h := got.Get("X-API-Key"); h != "k3y" || got.Get("Authorization") != "" {
+		t.Errorf("mpu: X-API-Key = %q, Authorization = %q", h, got.Get("Authorization"))
+	}
+
+	roundTrip(t, a, srv.URL+"/imx477/sensor", `{"nitidez_score":0.9}`)
+	ts := got.Get(TimestampHeader)
+	if ts != strconv.FormatInt(epoch.Unix(), 10) {
+		t.Errorf("imx: %s = %q, want %d", TimestampHeader, ts, epoch.Unix())
+	}
+	if want := Sign("s3cr3t", ts, "POST", "/imx477/sensor", []byte(body)); got.Get(SignatureHeader) != want {
+		t.Errorf("imx: %s = %q, want %q", SignatureHeader, got.Get(SignatureHeader), want)
+	}
+	if body != `{"nitidez_score":0.9}` || got.Get(KeyIDHeader) != "sim" {
+		t.Errorf("imx: cuerpo %q, key id %q", body, got.Get(KeyIDHeader))
+	}
+}
+
+// TestOAuth2 pide un token, lo reutiliza, lo renueva cuando vence y pide
+// otro cuando la API rechaza el cacheado.
+func TestOAuth2(t *testing.T) {
+	fake := clock.NewFake(epoch)
+	var issued atomic.Int32
+	var revoked atomic.Value
+	revoked.Store("")
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if r.FormValue("grant_type") != "client_credentials" || id != "sim" || secret != "pw" || r.FormValue("scope") != "sensors:write" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":"t%d","token_type":"Bearer","expires_in":300}`, issued.Add(1))
+	}))
+	defer tokens.Close()
+	var used string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		used = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if used == revoked.Load() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer api.Close()
+
+	a := &Authorizer{}
+	a.SetRules([]AuthRule{{Auth: &OAuth2{TokenURL: tokens.URL, ClientID: "sim", ClientSecret: "pw", Scopes: []string{"sensors:write"}, Clock: fake}}})
+
+	steps := []struct {
+		advance time.Duration
+		revoke  string
+		token   string
+	}{
+		{0, "", "t1"},
+		{time.Minute, "", "t1"},     // Sigue vigente
+		{4 * time.Minute, "", "t2"}, // Entra en el margen de renovación
+		{0, "t2", "t3"},             // La API lo rechaza: se pide otro
+	}
+	for i, s := range steps {
+		fake.Advance(s.advance)
+		revoked.Store(s.revoke)
+		if resp := roundTrip(t, a, api.URL+"/mpu/sensor", "{}"); resp.StatusCode != http.StatusCreated {
+			t.Errorf("paso %d: HTTP %d", i, resp.StatusCode)
+		}
+		if used != s.token {
+			t.Errorf("paso %d: token %q, want %q", i, used, s.token)
+		}
+	}
+	if n := issued.Load(); n != 3 {
+		t.Errorf("tokens pedidos = %d, want 3", n)
+	}
+
+	// Un 401 tardío con un token que otro worker ya renovó no descarta el
+	// nuevo
+	o := a.Rules()[0].Auth.(*OAuth2)
+	o.Invalidate("t2")
+	if token, _ := o.Token(); token != "t3" || issued.Load() != 3 {
+		t.Errorf("tras invalidar un token viejo: token %q, %d pedidos; want t3 y 3", token, issued.Load())
+	}
+}
+
+func TestLoadAuthConfig(t *testing.T) {
+	t.Setenv("GEOVA_TEST_SECRET", "desde-env")
+	t.Setenv("GEOVA_TEST_SCOPE", "sensors:write")
+	clk := clock.NewFake(epoch)
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	rules, err := LoadAuthConfig(write("ok.json", `{"endpoints": [
+		{"url": "", "type": "hmac", "secret": "${GEOVA_TEST_SECRET}"},
+		{"url": "http://localhost:8000/mpu", "type": "api_key", "header": "X-Geova-Key", "key": "k"},
+		{"url": "http://localhost:8000/imx477", "type": "oauth2", "token_url": "http://auth/token",
+		 "client_id": "sim", "client_secret": "pw", "scopes": ["${GEOVA_TEST_SCOPE}", "read"]}
+	]}`), clk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, ok := rules[0].Auth.(*HMAC); !ok || h.Secret != "desde-env" || h.Clock != clk {
+		t.Errorf("regla 0 = %#v, want HMAC con el secreto del entorno y el reloj", rules[0].Auth)
+	}
+	if got := rules[1].String(); got != "api_key en http://localhost:8000/mpu*" {
+		t.Errorf("regla 1 = %q", got)
+	}
+	if o, ok := rules[2].Auth.(*OAuth2); !ok || strings.Join(o.Scopes, " ") != "sensors:write read" {
+		t.Errorf("regla 2 = %#v, want scopes con la variable de entorno reemplazada", rules[2].Auth)
+	}
+
+	for src, want := range map[string]string{
+		`{"endpoints": [{"type": "bearer", "token": "${GEOVA_TEST_VACIA}"}]}`: `endpoint 1: bearer: falta "token"`,
+		`{"endpoints": [{"type": "kerberos"}]}`:                               "tipo de auth desconocido 'kerberos'",
+		`{"endpoints": [{"type": "bearer", "tokn": "x"}]}`:                    `unknown field "tokn"`,
+	} {
+		if _, err := LoadAuthConfig(write("bad.json", src), clk); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadAuthConfig(%s) = %v, want error con %q", src, err, want)
+		}
+	}
+
+	env := map[string]string{"GEOVA_AUTH_TYPE": "oauth2", "GEOVA_AUTH_TOKEN_URL": "http://auth/token",
+		"GEOVA_AUTH_CLIENT_ID": "sim", "GEOVA_AUTH_CLIENT_SECRET": "pw", "GEOVA_AUTH_SCOPES": "a,b"}
+	rules, err = AuthFromEnv(func(k string) string { return env[k] }, clk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o, ok := rules[0].Auth.(*OAuth2); !ok || o.ClientSecret != "pw" || len(o.Scopes) != 2 {
+		t.Errorf("AuthFromEnv = %#v", rules[0].Auth)
+	}
+}
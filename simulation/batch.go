@@ -0,0 +1,88 @@
+package simulation
+
+import (
+	"bytes"
+	"fmt"
+	"geova-simulation/codec"
+	"time"
+)
+
+// BatchFormat es cómo viajan varias lecturas en una sola petición.
+type BatchFormat string
+
+const (
+	BatchJSON   BatchFormat = "json"   // Un arreglo JSON
+	BatchNDJSON BatchFormat = "ndjson" // Una lectura JSON por línea
+)
+
+// ParseBatchFormat valida el nombre de un formato de lote.
+func ParseBatchFormat(s string) (BatchFormat, error) {
+	switch f := BatchFormat(s); f {
+	case BatchJSON, BatchNDJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("formato de lote desconocido '%s' (opciones: json, ndjson)", s)
+}
+
+// ContentType es el header Content-Type de un lote en el formato.
+func (f BatchFormat) ContentType() string {
+	if f == BatchNDJSON {
+		return "application/x-ndjson"
+	}
+	return "application/json"
+}
+
+// Batch son varias lecturas del mismo sensor que se envían juntas. Cada
+// lectura sale en la versión del Sender.
+type Batch struct {
+	Format BatchFormat   `json:"format"`
+	Items  []interface{} `json:"items"`
+}
+
+func encodeBatch(b Batch, version SchemaVersion, loc *time.Location) ([]byte, error) {
+	var buf bytes.Buffer
+	if b.Format != BatchNDJSON {
+		buf.WriteByte('[')
+	}
+	for i, item := range b.Items {
+		data, err := EncodePayload(item, version, codec.JSON, loc)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && b.Format != BatchNDJSON {
+			buf.WriteByte(',')
+		}
+		buf.Write(data)
+		if b.Format == BatchNDJSON {
+			buf.WriteByte('\n')
+		}
+	}
+	if b.Format != BatchNDJSON {
+		buf.WriteByte(']')
+	}
+	return buf.Bytes(), nil
+}
+
+// readings es cuántas lecturas lleva un payload.
+func readings(payload interface{}) int {
+	if b, ok := payload.(Batch); ok {
+		return len(b.Items)
+	}
+	return 1
+}
+
+// formatName es el formato con el que se serializa el payload, para los
+// mensajes de error.
+func (s *Sender) formatName(payload interface{}) string {
+	if b, ok := payload.(Batch); ok {
+		return string(b.Format)
+	}
+	return string(s.Encoding)
+}
+
+func (s *Sender) contentType(payload interface{}) string {
+	if b, ok := payload.(Batch); ok {
+		return b.Format.ContentType()
+	}
+	return s.Encoding.ContentType()
+}
@@ -0,0 +1,27 @@
+package simulation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeBatch(t *testing.T) {
+	items := []interface{}{GenerateRandomTFLunaData(4, epoch), GenerateRandomTFLunaData(4, epoch)}
+
+	data, err := encodeBatch(Batch{Format: BatchJSON, Items: items}, SchemaV1, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var array []TFLunaData
+	if err := json.Unmarshal(data, &array); err != nil || len(array) != 2 || array[1] != items[1] {
+		t.Errorf("arreglo = %s (%v)", data, err)
+	}
+
+	data, _ = encodeBatch(Batch{Format: BatchNDJSON, Items: items}, SchemaV1, time.UTC)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 || !json.Valid([]byte(lines[0])) {
+		t.Errorf("NDJSON = %q, want 2 líneas JSON", data)
+	}
+}
@@ -0,0 +1,140 @@
+package simulation
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ContentEncoding es la compresión del cuerpo de las peticiones.
+type ContentEncoding string
+
+const (
+	Identity ContentEncoding = "" // Sin comprimir
+	Gzip     ContentEncoding = "gzip"
+	Zstd     ContentEncoding = "zstd"
+)
+
+// ParseContentEncoding valida el nombre de una compresión; "none" y la
+// cadena vacía son sin comprimir.
+func ParseContentEncoding(s string) (ContentEncoding, error) {
+	switch e := ContentEncoding(s); e {
+	case Identity, Gzip, Zstd:
+		return e, nil
+	case "none":
+		return Identity, nil
+	}
+	return "", fmt.Errorf("compresión desconocida '%s' (opciones: none, gzip, zstd)", s)
+}
+
+// Compressor es un http.RoundTripper que comprime el cuerpo de las
+// peticiones con Encoding y agrega el header Content-Encoding. Las
+// peticiones que ya traen Content-Encoding pasan sin tocar.
+type Compressor struct {
+	Encoding ContentEncoding
+	Next     http.RoundTripper
+}
+
+func (c *Compressor) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := c.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if c.Encoding == Identity || req.Body == nil || req.Header.Get("Content-Encoding") != "" {
+		recordSent(req, req.ContentLength)
+		return next.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := compress(c.Encoding, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un RoundTripper no puede modificar la petición que recibe
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(compressed))
+	out.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	out.ContentLength = int64(len(compressed))
+	out.Header.Set("Content-Encoding", string(c.Encoding))
+	recordSent(req, out.ContentLength)
+	return next.RoundTrip(out)
+}
+
+var zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+	return zstd.NewWriter(nil)
+})
+
+func compress(e ContentEncoding, body []byte) ([]byte, error) {
+	switch e {
+	case Gzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case Zstd:
+		enc, err := zstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+		return enc.EncodeAll(body, nil), nil
+	}
+	return nil, fmt.Errorf("compresión desconocida '%s'", e)
+}
+
+// Decompress revierte compress; lo usa el servidor de prueba cmd/mockapi.
+func Decompress(e ContentEncoding, data []byte) ([]byte, error) {
+	switch e {
+	case Identity:
+		return data, nil
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	case Zstd:
+		dec, err := zstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+		return dec.DecodeAll(data, nil)
+	}
+	return nil, fmt.Errorf("compresión desconocida '%s'", e)
+}
+
+var zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+	return zstd.NewReader(nil)
+})
+
+// sentBytesKey lleva en el contexto de la petición dónde anotar cuántos
+// bytes de cuerpo salieron a la red.
+type sentBytesKey struct{}
+
+func withSentBytes(ctx context.Context, n *int64) context.Context {
+	return context.WithValue(ctx, sentBytesKey{}, n)
+}
+
+func recordSent(req *http.Request, n int64) {
+	if p, ok := req.Context().Value(sentBytesKey{}).(*int64); ok {
+		*p = n
+	}
+}
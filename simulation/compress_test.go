@@ -0,0 +1,54 @@
+package simulation
+
+import (
+	"bytes"
+	"geova-simulation/state"
+	"image/color"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompressedRequest(t *testing.T) {
+	for _, enc := range []ContentEncoding{Identity, Gzip, Zstd} {
+		t.Run(string(enc)+"-", func(t *testing.T) {
+			s, _ := newTestSender()
+			s.Compression.Encoding = enc
+
+			var header string
+			var raw, body []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Get("Content-Encoding")
+				raw, _ = io.ReadAll(r.Body)
+				var err error
+				if body, err = Decompress(ContentEncoding(header), raw); err != nil {
+					t.Errorf("Decompress: %v", err)
+				}
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer srv.Close()
+
+			visState := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+			batch := Batch{Format: BatchJSON}
+			for i := 0; i < 20; i++ {
+				batch.Items = append(batch.Items, GenerateRandomMPUData(4, 2, 1, epoch))
+			}
+			s.SendPOSTRequest(srv.URL, batch, "mpu", visState, Origin{Color: color.White})
+
+			packet := visState.Packets["mpu"]
+			if header != string(enc) || packet.ContentEncoding != string(enc) {
+				t.Errorf("Content-Encoding = %q (paquete %q), want %q", header, packet.ContentEncoding, enc)
+			}
+			if !bytes.Equal(body, packet.Body) {
+				t.Errorf("el servidor recibió otro cuerpo tras descomprimir")
+			}
+			if packet.SentBytes != len(raw) {
+				t.Errorf("SentBytes = %d, want %d", packet.SentBytes, len(raw))
+			}
+			if enc != Identity && packet.SentBytes >= len(packet.Body) {
+				t.Errorf("%s no redujo el cuerpo: %d → %d B", enc, len(packet.Body), packet.SentBytes)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"geova-simulation/i18n"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// FaultInjector es un http.RoundTripper que responde con un código de error
+// a las peticiones que coinciden con alguna falla y deja pasar al resto por
+// Next (http.DefaultTransport si es nil). Permite simular caídas del backend
+// sin tocar el servidor real; los escenarios lo usan para "la API responde
+// 503 durante 5 s".
+type FaultInjector struct {
+	Next http.RoundTripper
+
+	mu     sync.Mutex
+	faults map[string]int // prefijo de URL -> código HTTP
+}
+
+// Inject hace que las peticiones a URLs que empiezan con prefix respondan
+// status. Con prefix vacío afecta a todas.
+func (f *FaultInjector) Inject(prefix string, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.faults == nil {
+		f.faults = make(map[string]int)
+	}
+	f.faults[prefix] = status
+}
+
+// Recover quita la falla de prefix.
+func (f *FaultInjector) Recover(prefix string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.faults, prefix)
+}
+
+// Clear quita todas las fallas.
+func (f *FaultInjector) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = nil
+}
+
+// match busca la falla más específica (prefijo más largo) para url.
+func (f *FaultInjector) match(url string) (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	status, best := 0, -1
+	for prefix, s := range f.faults {
+		if strings.HasPrefix(url, prefix) && len(prefix) > best {
+			status, best = s, len(prefix)
+		}
+	}
+	return status, best >= 0
+}
+
+func (f *FaultInjector) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, ok := f.match(req.URL.String())
+	if !ok {
+		next := f.Next
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		return next.RoundTrip(req)
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	// Mismo formato que un HTTPException de FastAPI
+	detail, _ := json.Marshal(i18n.T("error.injected", status, http.StatusText(status)))
+	body := fmt.Sprintf(`{"detail":%s}`, detail)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,47 @@
+package simulation
+
+import (
+	"geova-simulation/i18n"
+	"geova-simulation/state"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFaultInjector(t *testing.T) {
+	s, _ := newTestSender()
+
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	send := func(path string) *state.PacketState {
+		visState := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+		s.SendPOSTRequest(srv.URL+path, GenerateRandomTFLunaData(4, epoch), "tfluna", visState, Origin{Color: color.White})
+		return visState.Packets["tfluna"]
+	}
+
+	s.Faults.Inject("", http.StatusServiceUnavailable)
+	s.Faults.Inject(srv.URL+"/mpu", http.StatusInternalServerError)
+
+	if p := send("/tfluna/sensor"); p.HTTPStatus != 503 || p.Status != state.Error {
+		t.Errorf("tfluna: HTTP %d %v, want 503 Error", p.HTTPStatus, p.Status)
+	} else if p.ErrorReason != i18n.T("error.injected", 503, "Service Unavailable") {
+		t.Errorf("ErrorReason = %q", p.ErrorReason)
+	}
+	if p := send("/mpu/sensor"); p.HTTPStatus != 500 {
+		t.Errorf("mpu: HTTP %d, want 500 (la falla más específica)", p.HTTPStatus)
+	}
+	if hits != 0 {
+		t.Errorf("el servidor recibió %d peticiones durante la falla", hits)
+	}
+
+	s.Faults.Recover("")
+	if p := send("/tfluna/sensor"); p.Status != state.ArrivedAtAPI || hits != 1 {
+		t.Errorf("tras Recover: %v, hits = %d, want ArrivedAtAPI y 1", p.Status, hits)
+	}
+}
@@ -0,0 +1,128 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"geova-simulation/clock"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth2 obtiene tokens con el flujo client credentials y los manda como
+// Bearer. El token se cachea hasta refreshMargin antes de que venza; los
+// workers que lo piden mientras se renueva esperan al mismo pedido.
+type OAuth2 struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// HTTPClient pide los tokens; por defecto uno con timeout, que no pasa
+	// por Auth ni por Faults.
+	HTTPClient *http.Client
+	Clock      clock.Clock // Vencimiento de los tokens; nil: el reloj del sistema
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// refreshMargin adelanta la renovación para que el token no venza en vuelo;
+// con tokens cortos se renuevan a la mitad de su vida.
+const refreshMargin = 30 * time.Second
+
+var tokenClient = &http.Client{Timeout: 10 * time.Second}
+
+func (o *OAuth2) Authorize(req *http.Request, _ []byte) error {
+	token, err := o.Token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (o *OAuth2) String() string { return "oauth2" }
+
+// Token retorna el token cacheado o pide uno nuevo si venció.
+func (o *OAuth2) Token() (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token != "" && readClock(o.Clock).Before(o.expires) {
+		return o.token, nil
+	}
+	token, expiresIn, err := o.fetch()
+	if err != nil {
+		return "", err
+	}
+	o.token = token
+	o.expires = readClock(o.Clock).Add(expiresIn - min(refreshMargin, expiresIn/2))
+	return token, nil
+}
+
+// Invalidate descarta el token cacheado si todavía es token, el que rechazó
+// la API; si otro worker ya lo renovó, el nuevo se conserva.
+func (o *OAuth2) Invalidate(token string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token == token {
+		o.token = ""
+	}
+}
+
+// fetch hace el pedido de RFC 6749 §4.4, con las credenciales del cliente
+// en Basic auth.
+func (o *OAuth2) fetch() (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(o.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.Scopes, " "))
+	}
+	req, err := http.NewRequest(http.MethodPost, o.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(o.ClientID), url.QueryEscape(o.ClientSecret))
+
+	client := o.HTTPClient
+	if client == nil {
+		client = tokenClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("pedido de token: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return "", 0, fmt.Errorf("pedido de token: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("pedido de token: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", 0, fmt.Errorf("respuesta de token inválida: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", 0, fmt.Errorf("respuesta de token sin access_token")
+	}
+	if body.TokenType != "" && !strings.EqualFold(body.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("tipo de token no soportado '%s'", body.TokenType)
+	}
+	// Sin expires_in se asume una hora, como la mayoría de los servidores
+	expiresIn := time.Hour
+	if body.ExpiresIn > 0 {
+		expiresIn = time.Duration(body.ExpiresIn) * time.Second
+	}
+	return body.AccessToken, expiresIn, nil
+}
@@ -0,0 +1,84 @@
+package simulation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geova-simulation/i18n"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// maxResponseBody limita cuánto del cuerpo de la respuesta se guarda en el
+// paquete; el resto se descarta.
+const maxResponseBody = 16 << 10
+
+// readBody lee hasta maxResponseBody bytes y avisa si había más.
+func readBody(r io.Reader) (body []byte, truncated bool, err error) {
+	body, err = io.ReadAll(io.LimitReader(r, maxResponseBody+1))
+	if len(body) > maxResponseBody {
+		return body[:maxResponseBody], true, err
+	}
+	return body, false, err
+}
+
+// fastAPIError es el cuerpo de error de FastAPI: detail es un texto
+// (HTTPException) o una lista de errores de validación (422).
+type fastAPIError struct {
+	Detail json.RawMessage `json:"detail"`
+}
+
+type validationError struct {
+	Loc []interface{} `json:"loc"`
+	Msg string        `json:"msg"`
+}
+
+// errorDetail resume el motivo de una respuesta de error. Si el cuerpo no
+// tiene el formato de FastAPI usa el texto estándar del código HTTP.
+func errorDetail(statusCode int, body []byte) string {
+	fallback := fmt.Sprintf("HTTP %d %s", statusCode, http.StatusText(statusCode))
+
+	var e fastAPIError
+	if json.Unmarshal(body, &e) != nil || len(e.Detail) == 0 {
+		return fallback
+	}
+
+	var text string
+	if json.Unmarshal(e.Detail, &text) == nil && text != "" {
+		return text
+	}
+
+	var list []validationError
+	if json.Unmarshal(e.Detail, &list) != nil || len(list) == 0 {
+		return fallback
+	}
+	reasons := make([]string, 0, len(list))
+	for _, v := range list {
+		reasons = append(reasons, fieldName(v.Loc)+": "+v.Msg)
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// fieldName toma el último elemento de loc (["body", "distancia_cm"]).
+func fieldName(loc []interface{}) string {
+	if len(loc) == 0 {
+		return "?"
+	}
+	return fmt.Sprint(loc[len(loc)-1])
+}
+
+// networkErrorReason describe un error de http.Post sin el detalle de la URL,
+// en el idioma de la interfaz.
+func networkErrorReason(err error) string {
+	var netErr interface{ Timeout() bool }
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return i18n.T("error.refused")
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return i18n.T("error.timeout")
+	default:
+		return i18n.T("error.network")
+	}
+}
@@ -0,0 +1,72 @@
+package simulation
+
+import (
+	"geova-simulation/state"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorDetail(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"detail texto", 404, `{"detail":"Not Found"}`, "Not Found"},
+		{
+			"validación 422", 422,
+			`{"detail":[{"loc":["body","distancia_cm"],"msg":"field required","type":"value_error.missing"},` +
+				`{"loc":["body",0],"msg":"value is not a valid dict"}]}`,
+			"distancia_cm: field required; 0: value is not a valid dict",
+		},
+		{"sin cuerpo", 500, ``, "HTTP 500 Internal Server Error"},
+		{"HTML", 502, `<html>Bad Gateway</html>`, "HTTP 502 Bad Gateway"},
+		{"detail vacío", 400, `{"detail":[]}`, "HTTP 400 Bad Request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorDetail(tt.status, []byte(tt.body)); got != tt.want {
+				t.Errorf("errorDetail = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadBodyTruncates(t *testing.T) {
+	body, truncated, err := readBody(strings.NewReader(strings.Repeat("x", maxResponseBody+10)))
+	if err != nil || !truncated || len(body) != maxResponseBody {
+		t.Errorf("len = %d, truncated = %v, err = %v", len(body), truncated, err)
+	}
+
+	body, truncated, _ = readBody(strings.NewReader("ok"))
+	if string(body) != "ok" || truncated {
+		t.Errorf("body = %q, truncated = %v", body, truncated)
+	}
+}
+
+func TestSendPOSTRequestStoresErrorDetail(t *testing.T) {
+	s, _ := newTestSender()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "abc123")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"detail":[{"loc":["body","roll"],"msg":"value is not a valid float"}]}`))
+	}))
+	defer srv.Close()
+
+	visState := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+	s.SendPOSTRequest(srv.URL, GenerateRandomMPUData(4, 0, 0, epoch), "mpu", visState, Origin{X: 80, Y: 200, Color: color.RGBA{B: 255, A: 255}})
+
+	packet := visState.Packets["mpu"]
+	if packet.ErrorReason != "roll: value is not a valid float" {
+		t.Errorf("ErrorReason = %q", packet.ErrorReason)
+	}
+	if got := packet.ResponseHeader.Get("X-Request-Id"); got != "abc123" {
+		t.Errorf("X-Request-Id = %q, want abc123", got)
+	}
+}
@@ -0,0 +1,350 @@
+package simulation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"geova-simulation/codec"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaVersion identifica una versión del formato de los payloads. v1 es el
+// de los tags JSON de datatypes.go; las demás renombran, quitan o convierten
+// campos de otra versión.
+type SchemaVersion string
+
+const (
+	SchemaV1 SchemaVersion = "v1"
+	SchemaV2 SchemaVersion = "v2"
+)
+
+// PayloadKinds son los tipos de payload, con el nombre del sensor que los
+// genera.
+var PayloadKinds = []string{"tfluna", "mpu", "imx"}
+
+var payloadTypes = map[string]reflect.Type{
+	"tfluna": reflect.TypeOf(TFLunaData{}),
+	"mpu":    reflect.TypeOf(MPUData{}),
+	"imx":    reflect.TypeOf(IMXData{}),
+}
+
+// Field es un campo de un payload en una versión del schema.
+type Field struct {
+	Name   string      // Nombre en esta versión
+	Number int         // Número de campo en proto/geova.proto
+	Source string      // Nombre en v1; vacío si el campo es una constante
+	Type   string      // Tipo JSON Schema
+	Format string      // Formato JSON Schema, opcional ("date-time")
+	Const  interface{} // Valor fijo cuando Source está vacío
+
+	convert func(v interface{}, loc *time.Location) interface{}
+}
+
+// PayloadSchema son los campos de un tipo de payload en una versión.
+type PayloadSchema struct {
+	Kind    string
+	Version SchemaVersion
+	Fields  []Field
+}
+
+var schemas = map[SchemaVersion]map[string]PayloadSchema{}
+
+// schemaVersionNumber es el número Protobuf de schema_version, fuera del
+// rango de los campos de v1.
+const schemaVersionNumber = 15
+
+func init() {
+	v1 := make(map[string]PayloadSchema, len(payloadTypes))
+	for kind, t := range payloadTypes {
+		v1[kind] = PayloadSchema{Kind: kind, Version: SchemaV1, Fields: structFields(t)}
+	}
+	schemas[SchemaV1] = v1
+
+	if err := deriveSchema(SchemaV2, SchemaV1, v2Fields); err != nil {
+		panic(err)
+	}
+	// v2 manda el timestamp en RFC 3339 con zona horaria
+	for _, s := range schemas[SchemaV2] {
+		for i := range s.Fields {
+			if s.Fields[i].Source == "timestamp" {
+				s.Fields[i].Format = "date-time"
+				s.Fields[i].convert = rfc3339
+			}
+		}
+	}
+}
+
+// v2Fields pasa los nombres de v1, mitad en español, al inglés que usa el
+// backend nuevo. Los campos que no aparecen conservan su nombre.
+var v2Fields = map[string]map[string]string{
+	"tfluna": {
+		"id_project":   "project_id",
+		"distancia_cm": "distance_cm",
+		"distancia_m":  "distance_m",
+		"fuerza_senal": "signal_strength",
+		"temperatura":  "temperature_c",
+	},
+	"mpu": {
+		"id_project": "project_id",
+		"apertura":   "aperture",
+	},
+	"imx": {
+		"id_project":                 "project_id",
+		"luminosidad_promedio":       "mean_luminosity",
+		"nitidez_score":              "sharpness_score",
+		"laser_detectado":            "laser_detected",
+		"calidad_frame":              "frame_quality",
+		"probabilidad_confiabilidad": "confidence",
+	},
+}
+
+// structFields arma los campos de v1 a partir de los tags JSON del struct.
+func structFields(t reflect.Type) []Field {
+	fields := make([]Field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		fields = append(fields, Field{Name: name, Number: i + 1, Source: name, Type: jsonType(f.Type.Kind())})
+	}
+	return fields
+}
+
+func jsonType(k reflect.Kind) string {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	}
+	return "string"
+}
+
+// deriveSchema registra version a partir de base. renames lleva, por tipo de
+// payload, el nombre en base de cada campo que cambia y su nombre nuevo; un
+// nombre vacío quita el campo. Las versiones derivadas llevan un campo
+// schema_version con su nombre, para que el backend sepa cómo leerlas.
+func deriveSchema(version, base SchemaVersion, renames map[string]map[string]string) error {
+	if _, ok := schemas[version]; ok {
+		return fmt.Errorf("la versión '%s' ya existe", version)
+	}
+	baseSchemas, ok := schemas[base]
+	if !ok {
+		return fmt.Errorf("versión base desconocida '%s'", base)
+	}
+	for kind := range renames {
+		if _, ok := baseSchemas[kind]; !ok {
+			return fmt.Errorf("tipo de payload desconocido '%s' (opciones: %v)", kind, PayloadKinds)
+		}
+	}
+
+	derived := make(map[string]PayloadSchema, len(baseSchemas))
+	for kind, s := range baseSchemas {
+		rename := renames[kind]
+		known := make(map[string]bool, len(s.Fields))
+		fields := make([]Field, 0, len(s.Fields)+1)
+		for _, f := range s.Fields {
+			known[f.Name] = true
+			if f.Name == "schema_version" {
+				continue
+			}
+			if name, ok := rename[f.Name]; ok {
+				if name == "" {
+					continue
+				}
+				f.Name = name
+			}
+			fields = append(fields, f)
+		}
+		for name := range rename {
+			if !known[name] {
+				return fmt.Errorf("%s: campo desconocido '%s' en la versión '%s'", kind, name, base)
+			}
+		}
+		fields = append(fields, Field{Name: "schema_version", Number: schemaVersionNumber, Type: "string", Const: string(version)})
+		// Un renombre no puede pisar a otro campo: el payload tendría claves
+		// repetidas
+		names := make(map[string]bool, len(fields))
+		for _, f := range fields {
+			if names[f.Name] {
+				return fmt.Errorf("%s: el campo '%s' aparece dos veces en la versión '%s'", kind, f.Name, version)
+			}
+			names[f.Name] = true
+		}
+		derived[kind] = PayloadSchema{Kind: kind, Version: version, Fields: fields}
+	}
+	schemas[version] = derived
+	return nil
+}
+
+// rfc3339 agrega la zona horaria al timestamp de v1. Las lecturas se crean
+// con el reloj del pipeline, así que loc es la zona de ese reloj y no la del
+// host.
+func rfc3339(v interface{}, loc *time.Location) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	t, err := time.ParseInLocation(timestampLayout, s, loc)
+	if err != nil {
+		return v
+	}
+	return t.Format(time.RFC3339)
+}
+
+// SchemaMapping es el archivo JSON de -schema: define una versión nueva a
+// partir de otra, campo por campo.
+//
+//	{
+//	  "version": "v2-staging",
+//	  "base": "v2",
+//	  "fields": {"tfluna": {"distance_cm": "distance", "temperature_c": ""}}
+//	}
+type SchemaMapping struct {
+	Version SchemaVersion                `json:"version"`
+	Base    SchemaVersion                `json:"base"`
+	Fields  map[string]map[string]string `json:"fields"`
+}
+
+// ParseSchema valida la versión de los payloads: el nombre de una versión
+// conocida o la ruta de un archivo .json con un SchemaMapping, que queda
+// registrado.
+func ParseSchema(s string) (SchemaVersion, error) {
+	if strings.HasSuffix(s, ".json") {
+		return LoadSchemaMapping(s)
+	}
+	if _, ok := schemas[SchemaVersion(s)]; !ok {
+		return "", fmt.Errorf("versión de schema desconocida '%s' (opciones: %v)", s, SchemaVersions())
+	}
+	return SchemaVersion(s), nil
+}
+
+// LoadSchemaMapping registra la versión definida en path y retorna su nombre.
+func LoadSchemaMapping(path string) (SchemaVersion, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var m SchemaMapping
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	if m.Version == "" {
+		return "", fmt.Errorf("%s: falta \"version\"", path)
+	}
+	if m.Base == "" {
+		m.Base = SchemaV1
+	}
+	if err := deriveSchema(m.Version, m.Base, m.Fields); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return m.Version, nil
+}
+
+// SchemaVersions lista las versiones registradas: v1, v2 y las cargadas de
+// archivos, en orden alfabético.
+func SchemaVersions() []SchemaVersion {
+	var custom []SchemaVersion
+	for v := range schemas {
+		if v != SchemaV1 && v != SchemaV2 {
+			custom = append(custom, v)
+		}
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i] < custom[j] })
+	return append([]SchemaVersion{SchemaV1, SchemaV2}, custom...)
+}
+
+// PayloadKind retorna el tipo de un payload ("tfluna", "mpu", "imx").
+func PayloadKind(payload interface{}) (string, bool) {
+	for kind, t := range payloadTypes {
+		if reflect.TypeOf(payload) == t {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// EncodePayload serializa payload en version y format; loc es la zona de
+// sus timestamps. Los payloads de otro tipo solo se pueden enviar como JSON
+// y salen tal cual.
+func EncodePayload(payload interface{}, version SchemaVersion, format codec.Format, loc *time.Location) ([]byte, error) {
+	kind, ok := PayloadKind(payload)
+	if !ok {
+		if format != codec.JSON {
+			return nil, fmt.Errorf("%s: no se puede serializar %T", format, payload)
+		}
+		return json.Marshal(payload)
+	}
+	s, ok := schemas[version][kind]
+	if !ok {
+		return nil, fmt.Errorf("versión de schema desconocida '%s'", version)
+	}
+
+	v := reflect.ValueOf(payload)
+	m := make(codec.Message, 0, len(s.Fields))
+	for _, f := range s.Fields {
+		value := f.Const
+		if f.Source != "" {
+			// Los campos de v1 están en el orden del struct
+			value = v.Field(f.Number - 1).Interface()
+			if f.convert != nil {
+				value = f.convert(value, loc)
+			}
+		}
+		m = append(m, codec.Field{Name: f.Name, Number: f.Number, Value: value})
+	}
+	return codec.Encode(format, m)
+}
+
+// encodePayload serializa el payload con la versión y el formato del
+// Sender. Los lotes van siempre como JSON.
+func (s *Sender) encodePayload(payload interface{}) ([]byte, error) {
+	loc := s.Clock.Now().Location()
+	if b, ok := payload.(Batch); ok {
+		return encodeBatch(b, s.Schema, loc)
+	}
+	return EncodePayload(payload, s.Schema, s.Encoding, loc)
+}
+
+// JSONSchema describe un tipo de payload en una versión como JSON Schema
+// (draft 2020-12), para validarlo del lado del backend.
+func JSONSchema(kind string, version SchemaVersion) ([]byte, error) {
+	s, ok := schemas[version][kind]
+	if !ok {
+		if _, ok := schemas[version]; !ok {
+			return nil, fmt.Errorf("versión de schema desconocida '%s'", version)
+		}
+		return nil, fmt.Errorf("tipo de payload desconocido '%s' (opciones: %v)", kind, PayloadKinds)
+	}
+
+	type property struct {
+		Type   string      `json:"type"`
+		Format string      `json:"format,omitempty"`
+		Const  interface{} `json:"const,omitempty"`
+	}
+	doc := struct {
+		Schema               string              `json:"$schema"`
+		Title                string              `json:"title"`
+		Type                 string              `json:"type"`
+		Properties           map[string]property `json:"properties"`
+		Required             []string            `json:"required"`
+		AdditionalProperties bool                `json:"additionalProperties"`
+	}{
+		Schema:     "https://json-schema.org/draft/2020-12/schema",
+		Title:      fmt.Sprintf("%s %s", payloadTypes[kind].Name(), version),
+		Type:       "object",
+		Properties: make(map[string]property, len(s.Fields)),
+	}
+	for _, f := range s.Fields {
+		doc.Properties[f.Name] = property{Type: f.Type, Format: f.Format, Const: f.Const}
+		doc.Required = append(doc.Required, f.Name)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,185 @@
+package simulation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"geova-simulation/codec"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodePayloadV2(t *testing.T) {
+	// La zona del host no debe cambiar el timestamp, que sigue a la del reloj
+	prev := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*3600)
+	t.Cleanup(func() { time.Local = prev })
+	d := TFLunaData{IDProject: 4, DistanciaCm: 175, DistanciaM: 1.75, FuerzaSenal: 5200, Temperatura: 52.5, Event: true,
+		Timestamp: epoch.Format(timestampLayout)}
+
+	data, err := EncodePayload(d, SchemaV2, codec.JSON, epoch.Location())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	json.Unmarshal(data, &got)
+
+	want := map[string]interface{}{
+		"project_id": 4.0, "distance_cm": 175.0, "distance_m": 1.75, "signal_strength": 5200.0,
+		"temperature_c": 52.5, "event": true, "schema_version": "v2",
+		"timestamp": epoch.UTC().Format(time.RFC3339),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("campos = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+// TestEncodePayloadV1 comprueba que en v1 el cuerpo es el mismo que con
+// json.Marshal del struct.
+func TestEncodePayloadV1(t *testing.T) {
+	d := GenerateRandomMPUData(4, 3, -1.5, epoch)
+	got, err := EncodePayload(d, SchemaV1, codec.JSON, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := json.Marshal(d); !bytes.Equal(got, want) {
+		t.Errorf("cuerpo = %s, want %s", got, want)
+	}
+}
+
+func TestLoadSchemaMapping(t *testing.T) {
+	write := func(name, content string) string {
+		path := filepath.Join(t.TempDir(), name)
+		os.WriteFile(path, []byte(content), 0o644)
+		return path
+	}
+
+	path := write("m.json", `{"version": "test-ok", "base": "v2",
+		"fields": {"imx": {"confidence": "score", "resolution": ""}}}`)
+	version, err := LoadSchemaMapping(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { delete(schemas, version) })
+	data, _ := EncodePayload(IMXData{Confiabilidad: 0.9, Resolution: "640x480"}, version, codec.JSON, time.UTC)
+	var m map[string]interface{}
+	json.Unmarshal(data, &m)
+	if _, ok := m["resolution"]; ok || m["schema_version"] != "test-ok" {
+		t.Errorf("payload = %v, want sin resolution y con schema_version test-ok", m)
+	}
+	if m["score"] != 0.9 {
+		t.Errorf("score = %v, want 0.9", m["score"])
+	}
+
+	tests := []struct {
+		content, want string
+	}{
+		{`{"base": "v1"}`, `falta "version"`},
+		{`{"version": "test-ok"}`, "ya existe"},
+		{`{"version": "x1", "base": "v9"}`, "versión base desconocida 'v9'"},
+		{`{"version": "x2", "fields": {"lidar": {}}}`, "tipo de payload desconocido 'lidar'"},
+		{`{"version": "x3", "fields": {"mpu": {"aperture": "a"}}}`, "campo desconocido 'aperture'"},
+		{`{"version": "x4", "campos": {}}`, "unknown field"},
+		{`{"version": "x5", "fields": {"mpu": {"roll": "pitch"}}}`, "el campo 'pitch' aparece dos veces"},
+		{`{"version": "x6", "fields": {"mpu": {"roll": "schema_version"}}}`, "el campo 'schema_version' aparece dos veces"},
+	}
+	for _, tt := range tests {
+		if _, err := LoadSchemaMapping(write("bad.json", tt.content)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.content, err, tt.want)
+		}
+	}
+}
+
+// TestJSONSchemaMatchesPayload comprueba que los campos del JSON Schema son
+// exactamente los que se envían en cada versión.
+func TestJSONSchemaMatchesPayload(t *testing.T) {
+	payloads := map[string]interface{}{
+		"tfluna": GenerateRandomTFLunaData(4, epoch),
+		"mpu":    GenerateRandomMPUData(4, 0, 0, epoch),
+		"imx":    GenerateRandomIMXData(4, epoch),
+	}
+	for _, version := range []SchemaVersion{SchemaV1, SchemaV2} {
+		for kind, payload := range payloads {
+			doc, err := JSONSchema(kind, version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var schema struct {
+				Properties map[string]interface{} `json:"properties"`
+			}
+			json.Unmarshal(doc, &schema)
+
+			data, _ := EncodePayload(payload, version, codec.JSON, time.UTC)
+			var sent map[string]interface{}
+			json.Unmarshal(data, &sent)
+
+			if len(sent) != len(schema.Properties) {
+				t.Errorf("%s %s: %d campos enviados, %d en el schema", version, kind, len(sent), len(schema.Properties))
+			}
+			for name := range sent {
+				if _, ok := schema.Properties[name]; !ok {
+					t.Errorf("%s %s: falta '%s' en el schema", version, kind, name)
+				}
+			}
+		}
+	}
+}
+
+// TestProtoMatchesSchema comprueba que proto/geova.proto tiene los campos de
+// v1, con sus números y tipos, más schema_version.
+func TestProtoMatchesSchema(t *testing.T) {
+	data, err := os.ReadFile("../proto/geova.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	protoTypes := map[string]string{"int32": "integer", "double": "number", "bool": "boolean", "string": "string"}
+	messages := make(map[string][]Field)
+	var current string
+	for _, line := range strings.Split(string(data), "\n") {
+		f := strings.Fields(strings.TrimSuffix(strings.TrimSpace(line), ";"))
+		switch {
+		case len(f) == 3 && f[0] == "message":
+			current = f[1]
+		case len(f) == 4 && f[2] == "=" && current != "":
+			n, _ := strconv.Atoi(f[3])
+			messages[current] = append(messages[current], Field{Name: f[1], Number: n, Type: protoTypes[f[0]]})
+		}
+	}
+
+	for _, kind := range PayloadKinds {
+		want := append([]Field{}, schemas[SchemaV1][kind].Fields...)
+		want = append(want, Field{Name: "schema_version", Number: schemaVersionNumber, Type: "string"})
+		got := messages[payloadTypes[kind].Name()]
+		if len(got) != len(want) {
+			t.Errorf("%s: %d campos en el .proto, want %d", kind, len(got), len(want))
+			continue
+		}
+		for i, w := range want {
+			if g := got[i]; g.Name != w.Name || g.Number != w.Number || g.Type != w.Type {
+				t.Errorf("%s: campo %d = %s %d (%s), want %s %d (%s)", kind, i, g.Name, g.Number, g.Type, w.Name, w.Number, w.Type)
+			}
+		}
+	}
+}
+
+func TestSchemaVersionsOrder(t *testing.T) {
+	for _, v := range []SchemaVersion{"test-b", "test-a"} {
+		if err := deriveSchema(v, SchemaV1, nil); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { delete(schemas, v) })
+	}
+	got := fmt.Sprint(SchemaVersions())
+	if want := "[v1 v2 test-a test-b]"; got != want {
+		t.Errorf("SchemaVersions() = %s, want %s", got, want)
+	}
+}
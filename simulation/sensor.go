@@ -0,0 +1,42 @@
+package simulation
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// MPUSensor simula un MPU que sigue la inclinación real del trípode con
+// retardo (filtro de primer orden con constante Lag) y ruido gaussiano de
+// desviación Noise grados. Se usa en el modo streaming, donde cada muestra
+// lee la inclinación del momento.
+type MPUSensor struct {
+	ProjectID int
+	Lag       time.Duration
+	Noise     float64
+
+	roll, pitch float64
+	last        time.Time
+	primed      bool
+}
+
+// Sample acerca la lectura filtrada a (roll, pitch) según el tiempo pasado
+// desde la muestra anterior y genera el MPUData correspondiente. La primera
+// muestra parte directamente de la inclinación real.
+func (s *MPUSensor) Sample(roll, pitch float64, now time.Time) MPUData {
+	if !s.primed || s.Lag <= 0 {
+		s.roll, s.pitch = roll, pitch
+		s.primed = true
+	} else {
+		alpha := 1 - math.Exp(-now.Sub(s.last).Seconds()/s.Lag.Seconds())
+		s.roll += alpha * (roll - s.roll)
+		s.pitch += alpha * (pitch - s.pitch)
+	}
+	s.last = now
+
+	return GenerateRandomMPUData(s.ProjectID,
+		s.roll+rand.NormFloat64()*s.Noise,
+		s.pitch+rand.NormFloat64()*s.Noise,
+		now,
+	)
+}
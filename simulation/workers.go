@@ -2,8 +2,11 @@ package simulation
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
+	"geova-simulation/clock"
+	"geova-simulation/codec"
+	"geova-simulation/i18n"
 	"geova-simulation/state"
 	"image/color"
 	"math/rand"
@@ -11,9 +14,43 @@ import (
 	"time"
 )
 
-func GenerateRandomIMXData() IMXData {
+const timestampLayout = "2006-01-02 15:04:05"
+
+// Sender envía las lecturas de los workers. Clock marca las transiciones y
+// la latencia simulada antes de cada POST; el pipeline le pasa el suyo, así
+// que workers y FSM comparten reloj. Schema y Encoding eligen el formato del
+// cuerpo y Client lo envía por Compression, Auth y Faults, en ese orden.
+type Sender struct {
+	Clock    clock.Clock
+	Schema   SchemaVersion
+	Encoding codec.Format
+
+	Compression *Compressor
+	Auth        *Authorizer
+	Faults      *FaultInjector
+	Client      *http.Client
+}
+
+// NewSender arma un Sender que envía v1 en JSON, sin compresión ni
+// credenciales.
+func NewSender(clk clock.Clock) *Sender {
+	faults := &FaultInjector{}
+	auth := &Authorizer{Next: faults}
+	compression := &Compressor{Next: auth}
+	return &Sender{
+		Clock:       clk,
+		Schema:      SchemaV1,
+		Encoding:    codec.JSON,
+		Compression: compression,
+		Auth:        auth,
+		Faults:      faults,
+		Client:      &http.Client{Transport: compression},
+	}
+}
+
+func GenerateRandomIMXData(projectID int, now time.Time) IMXData {
 	return IMXData{
-		IDProject:      4,
+		IDProject:      projectID,
 		Resolution:     "640x480",
 		Luminosidad:    5.0 + rand.Float64()*10.0,
 		Nitidez:        4.0 + rand.Float64()*2.0,
@@ -21,89 +58,137 @@ func GenerateRandomIMXData() IMXData {
 		CalidadFrame:   20.0,
 		Confiabilidad:  0.8 + rand.Float64()*0.2,
 		Event:          true,
-		Timestamp:      time.Now().Format("2006-01-02 15:04:05"),
+		Timestamp:      now.Format(timestampLayout),
 	}
 }
 
-func GenerateRandomMPUData(tilt float64) MPUData {
+// GenerateRandomMPUData simula el MPU con el trípode inclinado roll y pitch
+// grados; el resto de ejes lleva ruido.
+func GenerateRandomMPUData(projectID int, roll, pitch float64, now time.Time) MPUData {
 	return MPUData{
-		IDProject: 4,
+		IDProject: projectID,
 		Ax:        0.1 + rand.Float64()*0.1,
 		Ay:        -0.05 + rand.Float64()*0.1,
 		Az:        9.8 + rand.Float64()*0.1,
 		Gx:        0.01 + rand.Float64()*0.02,
 		Gy:        0.02 + rand.Float64()*0.02,
 		Gz:        0.03 + rand.Float64()*0.02,
-		Roll:      tilt,
-		Pitch:     0.5 + rand.Float64()*1.0,
-		Apertura:  tilt * 1.5,
+		Roll:      roll,
+		Pitch:     pitch,
+		Apertura:  roll * 1.5,
 		Event:     true,
-		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+		Timestamp: now.Format(timestampLayout),
 	}
 }
 
-func GenerateRandomTFLunaData() TFLunaData {
+func GenerateRandomTFLunaData(projectID int, now time.Time) TFLunaData {
 	distCm := 150 + rand.Intn(150)
 	return TFLunaData{
-		IDProject:   4,
+		IDProject:   projectID,
 		DistanciaCm: distCm,
 		DistanciaM:  float64(distCm) / 100.0,
 		FuerzaSenal: 5000 + rand.Intn(1000),
 		Temperatura: 50.0 + rand.Float64()*5.0,
 		Event:       true,
-		Timestamp:   time.Now().Format("2006-01-02 15:04:05"),
+		Timestamp:   now.Format(timestampLayout),
 	}
 }
 
-func SendPOSTRequest(url string, payload interface{}, packetID string,
-	visState *state.VisualState, startY float64, c color.Color) {
+// Origin es de dónde sale un paquete: el dispositivo que lo envía, su
+// posición en la escena y el color del sensor.
+type Origin struct {
+	Device string
+	X, Y   float64
+	Color  color.Color
+}
+
+func (s *Sender) SendPOSTRequest(url string, payload interface{}, packetID string,
+	visState *state.VisualState, from Origin) {
+
+	data, err := s.encodePayload(payload)
 
 	visState.Mutex.Lock()
+	// El destino es provisorio: la FSM lo apunta a la Python API.
 	packet := &state.PacketState{
 		ID:              packetID,
 		Active:          true,
-		X:               80.0,
-		Y:               startY,
-		TargetX:         250.0,
-		TargetY:         200.0,
-		Color:           c,
-		Status:          state.SendingToAPI,
+		X:               from.X,
+		Y:               from.Y,
+		TargetX:         from.X,
+		TargetY:         from.Y,
+		Color:           from.Color,
+		Device:          from.Device,
 		Payload:         payload,
 		ProcessingTimer: 0,
+		URL:             url,
+		Body:            data,
+		ContentType:     s.contentType(payload),
+		ContentEncoding: string(s.Compression.Encoding),
+		Readings:        readings(payload),
 	}
+	packet.SetStatus(state.SendingToAPI, s.Clock.Now())
 	visState.Packets[packetID] = packet
 	visState.Mutex.Unlock()
 
-	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		fmt.Printf("[%s] Error al serializar JSON: %v\n", packetID, err)
+		fmt.Printf("[%s] Error al serializar como %s: %v\n", packetID, s.formatName(payload), err)
 		visState.Mutex.Lock()
-		visState.Packets[packetID].Status = state.Error
+		packet.ErrorReason = i18n.T("error.encode", s.formatName(payload))
+		packet.SetStatus(state.Error, s.Clock.Now())
 		visState.Mutex.Unlock()
 		return
 	}
 
-	time.Sleep(time.Duration(500+rand.Intn(500)) * time.Millisecond)
+	s.Clock.Sleep(time.Duration(500+rand.Intn(500)) * time.Millisecond)
 
 	fmt.Printf("[%s] Enviando POST a %s\n", packetID, url)
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-
-	visState.Mutex.Lock()
-	defer visState.Mutex.Unlock()
-
+	var sent int64
+	resp, err := s.post(url, s.contentType(payload), data, &sent)
 	if err != nil {
 		fmt.Printf("[%s] Error en HTTP: %v\n", packetID, err)
-		visState.Packets[packetID].Status = state.Error
+		visState.Mutex.Lock()
+		packet.SentBytes = int(sent)
+		packet.Response = err.Error()
+		packet.ErrorReason = networkErrorReason(err)
+		packet.SetStatus(state.Error, s.Clock.Now())
+		visState.Mutex.Unlock()
 		return
 	}
 	defer resp.Body.Close()
 
+	// El cuerpo se lee antes de tomar el mutex para no bloquear la UI
+	body, truncated, readErr := readBody(resp.Body)
+
+	visState.Mutex.Lock()
+	defer visState.Mutex.Unlock()
+
+	packet.SentBytes = int(sent)
+	packet.HTTPStatus = resp.StatusCode
+	packet.ResponseHeader = resp.Header.Clone()
+	packet.Response = string(body)
+	packet.ResponseTruncated = truncated
+	if readErr != nil {
+		fmt.Printf("[%s] Error al leer la respuesta: %v\n", packetID, readErr)
+	}
+
 	if resp.StatusCode >= 400 {
-		fmt.Printf("[%s] Error HTTP %d\n", packetID, resp.StatusCode)
-		visState.Packets[packetID].Status = state.Error
+		packet.ErrorReason = errorDetail(resp.StatusCode, body)
+		fmt.Printf("[%s] Error HTTP %d: %s\n", packetID, resp.StatusCode, packet.ErrorReason)
+		packet.SetStatus(state.Error, s.Clock.Now())
 		return
 	}
 
 	fmt.Printf("[%s] ✓ Petición exitosa (HTTP %d)\n", packetID, resp.StatusCode)
-	visState.Packets[packetID].Status = state.ArrivedAtAPI
+	packet.SetStatus(state.ArrivedAtAPI, s.Clock.Now())
+}
+
+// post envía el cuerpo con Client y anota en sent los bytes que salieron a la
+// red, que con compresión son menos que len(body).
+func (s *Sender) post(url, contentType string, body []byte, sent *int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(withSentBytes(context.Background(), sent), http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return s.Client.Do(req)
 }
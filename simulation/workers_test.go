@@ -0,0 +1,259 @@
+package simulation
+
+import (
+	"encoding/json"
+	"geova-simulation/clock"
+	"geova-simulation/codec"
+	"geova-simulation/i18n"
+	"geova-simulation/state"
+	"image/color"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+
+// newTestSender arma un Sender con un reloj falso, que evita la latencia
+// simulada de SendPOSTRequest y fija los timestamps.
+func newTestSender() (*Sender, *clock.Fake) {
+	fake := clock.NewFake(epoch)
+	return NewSender(fake), fake
+}
+
+func between(t *testing.T, name string, v, min, max float64) {
+	t.Helper()
+	if v < min || v > max {
+		t.Errorf("%s = %v, fuera de [%v, %v]", name, v, min, max)
+	}
+}
+
+func TestGenerateRandomTFLunaData(t *testing.T) {
+	for i := 0; i < 500; i++ {
+		d := GenerateRandomTFLunaData(4, epoch)
+		between(t, "DistanciaCm", float64(d.DistanciaCm), 150, 299)
+		if d.DistanciaM != float64(d.DistanciaCm)/100.0 {
+			t.Errorf("DistanciaM = %v, want %v", d.DistanciaM, float64(d.DistanciaCm)/100.0)
+		}
+		between(t, "FuerzaSenal", float64(d.FuerzaSenal), 5000, 5999)
+		between(t, "Temperatura", d.Temperatura, 50, 55)
+		if d.IDProject != 4 || !d.Event {
+			t.Errorf("IDProject/Event = %d/%v, want 4/true", d.IDProject, d.Event)
+		}
+		if d.Timestamp != "2025-03-14 09:26:53" {
+			t.Errorf("Timestamp = %q", d.Timestamp)
+		}
+	}
+}
+
+func TestGenerateRandomMPUData(t *testing.T) {
+	for _, tilt := range []float64{-15, -2.5, 0, 7.5, 15} {
+		d := GenerateRandomMPUData(4, tilt, -tilt/2, epoch)
+		if d.Roll != tilt || d.Apertura != tilt*1.5 || d.Pitch != -tilt/2 {
+			t.Errorf("tilt %v: Roll/Pitch/Apertura = %v/%v/%v", tilt, d.Roll, d.Pitch, d.Apertura)
+		}
+		between(t, "Ax", d.Ax, 0.1, 0.2)
+		between(t, "Ay", d.Ay, -0.05, 0.05)
+		between(t, "Az", d.Az, 9.8, 9.9)
+		between(t, "Gx", d.Gx, 0.01, 0.03)
+		between(t, "Gy", d.Gy, 0.02, 0.04)
+		between(t, "Gz", d.Gz, 0.03, 0.05)
+	}
+}
+
+func TestGenerateRandomIMXData(t *testing.T) {
+	for i := 0; i < 500; i++ {
+		d := GenerateRandomIMXData(4, epoch)
+		between(t, "Luminosidad", d.Luminosidad, 5, 15)
+		between(t, "Nitidez", d.Nitidez, 4, 6)
+		between(t, "Confiabilidad", d.Confiabilidad, 0.8, 1.0)
+		if d.Resolution != "640x480" || d.CalidadFrame != 20.0 {
+			t.Errorf("Resolution/CalidadFrame = %q/%v", d.Resolution, d.CalidadFrame)
+		}
+	}
+}
+
+// TestJSONFieldNames fija los nombres de campo que espera el backend.
+func TestJSONFieldNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload interface{}
+		want    []string
+	}{
+		{"TFLunaData", TFLunaData{}, []string{
+			"distancia_cm", "distancia_m", "event", "fuerza_senal", "id_project",
+			"temperatura", "timestamp",
+		}},
+		{"MPUData", MPUData{}, []string{
+			"apertura", "ax", "ay", "az", "event", "gx", "gy", "gz", "id_project",
+			"pitch", "roll", "timestamp",
+		}},
+		{"IMXData", IMXData{}, []string{
+			"calidad_frame", "event", "id_project", "laser_detectado", "luminosidad_promedio",
+			"nitidez_score", "probabilidad_confiabilidad", "resolution", "timestamp",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]interface{}
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(fields))
+			for k := range fields {
+				got = append(got, k)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("campos = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("campos = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSendPOSTRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantStatus state.PacketStatus
+	}{
+		{"éxito 200", http.StatusOK, state.ArrivedAtAPI},
+		{"éxito 201", http.StatusCreated, state.ArrivedAtAPI},
+		{"error 422", http.StatusUnprocessableEntity, state.Error},
+		{"error 404", http.StatusNotFound, state.Error},
+		{"error 500", http.StatusInternalServerError, state.Error},
+		{"error 503", http.StatusServiceUnavailable, state.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestSender()
+
+			var gotBody TFLunaData
+			var gotContentType string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotContentType = r.Header.Get("Content-Type")
+				body, _ := io.ReadAll(r.Body)
+				json.Unmarshal(body, &gotBody)
+				w.WriteHeader(tt.statusCode)
+				io.WriteString(w, `{"ok":true}`)
+			}))
+			defer srv.Close()
+
+			payload := GenerateRandomTFLunaData(4, epoch)
+			visState := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+			from := Origin{Device: "geova2", X: 80, Y: 180, Color: color.RGBA{R: 255, A: 255}}
+			s.SendPOSTRequest(srv.URL+"/tfluna/sensor", payload, "tfluna", visState, from)
+
+			packet := visState.Packets["tfluna"]
+			if packet == nil {
+				t.Fatal("SendPOSTRequest no registró el paquete")
+			}
+			if packet.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", packet.Status, tt.wantStatus)
+			}
+			if packet.Y != 180.0 || !packet.Active || packet.Device != "geova2" {
+				t.Errorf("Y/Active/Device = %v/%v/%q, want 180/true/geova2", packet.Y, packet.Active, packet.Device)
+			}
+			if gotContentType != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", gotContentType)
+			}
+			if gotBody != payload {
+				t.Errorf("body = %+v, want %+v", gotBody, payload)
+			}
+			if packet.ContentType != "application/json" || len(packet.Body) == 0 {
+				t.Errorf("ContentType/Body = %q/%d bytes, want application/json y el cuerpo enviado", packet.ContentType, len(packet.Body))
+			}
+			if packet.HTTPStatus != tt.statusCode || packet.Response != `{"ok":true}` {
+				t.Errorf("HTTPStatus/Response = %d/%q", packet.HTTPStatus, packet.Response)
+			}
+			if n := len(packet.Transitions); n != 2 || packet.Transitions[0].Status != state.SendingToAPI ||
+				packet.Transitions[1].Status != tt.wantStatus {
+				t.Errorf("Transitions = %+v, want [SendingToAPI %v]", packet.Transitions, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSendPOSTRequestConnectionRefused(t *testing.T) {
+	s, _ := newTestSender()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL + "/mpu/sensor"
+	srv.Close()
+
+	visState := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+	s.SendPOSTRequest(url, GenerateRandomMPUData(4, 0, 0, epoch), "mpu", visState, Origin{X: 80, Y: 200, Color: color.RGBA{B: 255, A: 255}})
+
+	packet := visState.Packets["mpu"]
+	if packet.Status != state.Error {
+		t.Errorf("Status = %v, want Error", packet.Status)
+	}
+	if want := i18n.T("error.refused"); packet.ErrorReason != want {
+		t.Errorf("ErrorReason = %q, want %q", packet.ErrorReason, want)
+	}
+}
+
+func TestSendPOSTRequestEncodeError(t *testing.T) {
+	s, _ := newTestSender()
+	s.Encoding = codec.Protobuf
+
+	visState := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+	s.SendPOSTRequest("http://localhost:1/x", struct{}{}, "x", visState, Origin{Color: color.White})
+
+	packet := visState.Packets["x"]
+	if want := i18n.T("error.encode", "protobuf"); packet.Status != state.Error || packet.ErrorReason != want {
+		t.Errorf("Status = %v, ErrorReason = %q, want Error y %q", packet.Status, packet.ErrorReason, want)
+	}
+
+	// El motivo sale en el idioma de la interfaz
+	t.Cleanup(func() { i18n.SetLang(i18n.Default) })
+	i18n.SetLang(i18n.English)
+	s.SendPOSTRequest("http://localhost:1/x", struct{}{}, "x", visState, Origin{Color: color.White})
+	if want := "could not serialize as protobuf"; visState.Packets["x"].ErrorReason != want {
+		t.Errorf("en: ErrorReason = %q, want %q", visState.Packets["x"].ErrorReason, want)
+	}
+}
+
+func TestSendPOSTRequestUsesClockForLatency(t *testing.T) {
+	s, fake := newTestSender()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	visState := &state.VisualState{Packets: make(map[string]*state.PacketState)}
+	s.SendPOSTRequest(srv.URL, GenerateRandomIMXData(4, epoch), "imx", visState, Origin{X: 80, Y: 220, Color: color.RGBA{G: 255, A: 255}})
+
+	elapsed := fake.Now().Sub(epoch)
+	if elapsed < 500*time.Millisecond || elapsed >= time.Second {
+		t.Errorf("latencia simulada = %v, want [500ms, 1s)", elapsed)
+	}
+}
+
+func TestMPUSensorLag(t *testing.T) {
+	s := &MPUSensor{Lag: time.Second}
+
+	if d := s.Sample(0, 0, epoch); d.Roll != 0 || d.Pitch != 0 {
+		t.Fatalf("primera muestra = %v/%v, want 0/0", d.Roll, d.Pitch)
+	}
+	// Tras una constante de tiempo el filtro recorre 1-1/e ≈ 63% del salto
+	d := s.Sample(10, -5, epoch.Add(time.Second))
+	between(t, "Roll", d.Roll, 6.3, 6.33)
+	between(t, "Pitch", d.Pitch, -3.17, -3.15)
+
+	d = s.Sample(10, -5, epoch.Add(10*time.Second))
+	between(t, "Roll tras 9s", d.Roll, 9.99, 10)
+}
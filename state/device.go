@@ -0,0 +1,33 @@
+package state
+
+import "image/color"
+
+// DeviceState es un Geova de la escena: la inclinación actual de su trípode
+// y lo último que llegó al frontend con sus datos.
+type DeviceState struct {
+	ID        string
+	Name      string
+	ProjectID int
+	Color     color.Color // Color de su etiqueta y de sus paquetes en pantalla
+
+	Tilt  float64 // Roll del trípode
+	Pitch float64
+
+	DisplayDistancia float64
+	DisplayRoll      float64
+	DisplayPitch     float64
+	DisplayNitidez   float64
+	DisplayLaser     bool
+
+	// Historial de lecturas que llegaron al frontend, para las gráficas.
+	History [MetricCount]Series
+}
+
+// ResetDisplay borra los valores del dashboard; el historial se conserva.
+func (d *DeviceState) ResetDisplay() {
+	d.DisplayDistancia = 0
+	d.DisplayRoll = 0
+	d.DisplayPitch = 0
+	d.DisplayNitidez = 0
+	d.DisplayLaser = false
+}
@@ -0,0 +1,86 @@
+package state
+
+// Metric identifica cada lectura de sensor que se guarda en el historial. Sus
+// nombres están en el catálogo de i18n (metric.*).
+type Metric int
+
+const (
+	MetricDistancia Metric = iota
+	MetricRoll
+	MetricPitch
+	MetricNitidez
+	MetricFuerzaSenal
+	MetricTemperatura
+	MetricCount
+)
+
+// DefaultSeriesCapacity es la cantidad de muestras que guarda una Series
+// creada con su valor cero.
+const DefaultSeriesCapacity = 120
+
+// Series es un buffer circular de muestras: al llenarse, cada Add descarta la
+// más antigua. El valor cero está listo para usarse.
+type Series struct {
+	values []float64
+	next   int
+	full   bool
+}
+
+func NewSeries(capacity int) *Series {
+	return &Series{values: make([]float64, capacity)}
+}
+
+func (s *Series) Add(v float64) {
+	if s.values == nil {
+		s.values = make([]float64, DefaultSeriesCapacity)
+	}
+	s.values[s.next] = v
+	s.next++
+	if s.next == len(s.values) {
+		s.next = 0
+		s.full = true
+	}
+}
+
+func (s *Series) Len() int {
+	if s.full {
+		return len(s.values)
+	}
+	return s.next
+}
+
+func (s *Series) Cap() int {
+	if s.values == nil {
+		return DefaultSeriesCapacity
+	}
+	return len(s.values)
+}
+
+// Values retorna una copia de las muestras, de la más antigua a la más nueva.
+func (s *Series) Values() []float64 {
+	out := make([]float64, 0, s.Len())
+	if s.full {
+		out = append(out, s.values[s.next:]...)
+	}
+	return append(out, s.values[:s.next]...)
+}
+
+// Stats retorna mínimo, máximo y promedio. ok es false si no hay muestras.
+func (s *Series) Stats() (min, max, avg float64, ok bool) {
+	n := s.Len()
+	if n == 0 {
+		return 0, 0, 0, false
+	}
+	min, max = s.values[0], s.values[0]
+	sum := 0.0
+	for _, v := range s.values[:n] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+		sum += v
+	}
+	return min, max, sum / float64(n), true
+}
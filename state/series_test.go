@@ -0,0 +1,36 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeriesWrapsAround(t *testing.T) {
+	s := NewSeries(3)
+	if _, _, _, ok := s.Stats(); ok {
+		t.Fatal("Stats de una serie vacía retornó ok")
+	}
+
+	for _, v := range []float64{1, 2, 3, 4, 5} {
+		s.Add(v)
+	}
+	if got, want := s.Values(), []float64{3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values = %v, want %v", got, want)
+	}
+	min, max, avg, ok := s.Stats()
+	if !ok || min != 3 || max != 5 || avg != 4 {
+		t.Errorf("Stats = %v, %v, %v, %v; want 3, 5, 4, true", min, max, avg, ok)
+	}
+}
+
+func TestSeriesZeroValue(t *testing.T) {
+	var s Series
+	s.Add(-2)
+	s.Add(7)
+	if got, want := s.Values(), []float64{-2, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values = %v, want %v", got, want)
+	}
+	if s.Cap() != DefaultSeriesCapacity {
+		t.Errorf("Cap = %d, want %d", s.Cap(), DefaultSeriesCapacity)
+	}
+}
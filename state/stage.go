@@ -0,0 +1,135 @@
+package state
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+type ServiceDist int
+
+const (
+	Fixed ServiceDist = iota
+	Uniform
+	Exponential
+)
+
+var serviceDistNames = [...]string{Fixed: "fixed", Uniform: "uniform", Exponential: "exponential"}
+
+func (d ServiceDist) String() string {
+	if int(d) < len(serviceDistNames) {
+		return serviceDistNames[d]
+	}
+	return "ServiceDist(" + strconv.Itoa(int(d)) + ")"
+}
+
+// ParseServiceDist valida el nombre de una distribución de servicio.
+func ParseServiceDist(s string) (ServiceDist, error) {
+	for d, name := range serviceDistNames {
+		if s == name {
+			return ServiceDist(d), nil
+		}
+	}
+	return 0, fmt.Errorf("distribución desconocida '%s' (opciones: fixed, uniform, exponential)", s)
+}
+
+// minServiceTime evita servicios instantáneos con la distribución exponencial.
+const minServiceTime = 50 * time.Millisecond
+
+// ServiceTime describe la distribución del tiempo de servicio de una etapa.
+// Spread solo aplica a Uniform (Mean ± Spread).
+type ServiceTime struct {
+	Dist   ServiceDist
+	Mean   time.Duration
+	Spread time.Duration
+}
+
+func (s ServiceTime) Sample() time.Duration {
+	var t time.Duration
+	switch s.Dist {
+	case Uniform:
+		t = s.Mean - s.Spread + time.Duration(rand.Int63n(int64(2*s.Spread)+1))
+	case Exponential:
+		t = time.Duration(rand.ExpFloat64() * float64(s.Mean))
+	default:
+		t = s.Mean
+	}
+	if t < minServiceTime {
+		t = minServiceTime
+	}
+	return t
+}
+
+// StageState modela una etapa del backend (Python API, RabbitMQ, WebSocket)
+// como un servidor con Capacity slots concurrentes y una cola FIFO.
+type StageState struct {
+	Name     string
+	X, Y     float64
+	Capacity int
+	Service  ServiceTime
+
+	InService []*PacketState
+	Queue     []*PacketState
+
+	BusyTime  time.Duration
+	TotalTime time.Duration
+}
+
+func NewStage(name string, x, y float64, capacity int, service ServiceTime) *StageState {
+	return &StageState{
+		Name:     name,
+		X:        x,
+		Y:        y,
+		Capacity: capacity,
+		Service:  service,
+	}
+}
+
+func (s *StageState) HasFreeSlot() bool {
+	return len(s.InService) < s.Capacity
+}
+
+func (s *StageState) Busy() bool {
+	return len(s.InService) > 0
+}
+
+// Tick acumula la ocupación de los slots durante dt para calcular la utilización.
+func (s *StageState) Tick(dt time.Duration) {
+	s.TotalTime += dt
+	s.BusyTime += dt * time.Duration(len(s.InService))
+}
+
+// Utilisation devuelve la fracción de tiempo-slot ocupada desde el último reset.
+func (s *StageState) Utilisation() float64 {
+	if s.TotalTime == 0 || s.Capacity == 0 {
+		return 0
+	}
+	return float64(s.BusyTime) / float64(s.TotalTime*time.Duration(s.Capacity))
+}
+
+func (s *StageState) Reset() {
+	s.InService = nil
+	s.Queue = nil
+	s.BusyTime = 0
+	s.TotalTime = 0
+}
+
+func (s *StageState) Remove(packet *PacketState) {
+	for i, p := range s.InService {
+		if p == packet {
+			s.InService = append(s.InService[:i], s.InService[i+1:]...)
+			return
+		}
+	}
+}
+
+// Dequeue saca el primer paquete en espera, o nil si la cola está vacía.
+func (s *StageState) Dequeue() *PacketState {
+	if len(s.Queue) == 0 {
+		return nil
+	}
+	head := s.Queue[0]
+	s.Queue = s.Queue[1:]
+	return head
+}
@@ -2,7 +2,10 @@ package state
 
 import (
 	"image/color"
+	"net/http"
+	"strconv"
 	"sync"
+	"time"
 )
 
 type PacketStatus int
@@ -11,16 +14,43 @@ const (
 	Idle PacketStatus = iota
 	SendingToAPI
 	ArrivedAtAPI
+	QueuedAtAPI
 	ProcessingAtAPI
 	SendingToRabbit
+	QueuedAtRabbit
 	ProcessingAtRabbit
 	SendingToWebsocket
+	QueuedAtWebsocket
 	ProcessingAtWebsocket
 	SendingToFrontend
 	Done
 	Error
 )
 
+var statusNames = [...]string{
+	Idle:                  "Idle",
+	SendingToAPI:          "SendingToAPI",
+	ArrivedAtAPI:          "ArrivedAtAPI",
+	QueuedAtAPI:           "QueuedAtAPI",
+	ProcessingAtAPI:       "ProcessingAtAPI",
+	SendingToRabbit:       "SendingToRabbit",
+	QueuedAtRabbit:        "QueuedAtRabbit",
+	ProcessingAtRabbit:    "ProcessingAtRabbit",
+	SendingToWebsocket:    "SendingToWebsocket",
+	QueuedAtWebsocket:     "QueuedAtWebsocket",
+	ProcessingAtWebsocket: "ProcessingAtWebsocket",
+	SendingToFrontend:     "SendingToFrontend",
+	Done:                  "Done",
+	Error:                 "Error",
+}
+
+func (s PacketStatus) String() string {
+	if int(s) < len(statusNames) {
+		return statusNames[s]
+	}
+	return "PacketStatus(" + strconv.Itoa(int(s)) + ")"
+}
+
 type PacketState struct {
 	ID               string
 	Active           bool
@@ -29,20 +59,69 @@ type PacketState struct {
 	Color            color.Color
 	Status           PacketStatus
 	Payload          interface{}
-	ProcessingTimer  int
+	ProcessingTimer  time.Duration
+	Device           string // ID del dispositivo que lo envió
+	Readings         int    // Lecturas que lleva; más de una si es un lote
+
+	// Datos para el inspector de paquetes
+	URL               string
+	Body              []byte // Cuerpo de la petición tal como se envió
+	ContentType       string
+	ContentEncoding   string // Compresión del cuerpo; vacío si no se comprimió
+	SentBytes         int    // Bytes del cuerpo en la red, ya comprimido
+	HTTPStatus        int    // 0 si la petición no llegó a responder
+	ResponseHeader    http.Header
+	Response          string // Cuerpo de la respuesta (o el error de red)
+	ResponseTruncated bool   // El cuerpo superaba el límite y se cortó
+	ErrorReason       string // Motivo corto del error para mostrar en pantalla
+	Transitions       []Transition
+}
+
+// Transition registra cuándo un paquete entró a un estado.
+type Transition struct {
+	Status PacketStatus
+	At     time.Time
+}
+
+// SetStatus cambia el estado del paquete y anota la transición.
+func (p *PacketState) SetStatus(s PacketStatus, at time.Time) {
+	p.Status = s
+	p.Transitions = append(p.Transitions, Transition{Status: s, At: at})
 }
 
 type VisualState struct {
 	Mutex   sync.Mutex
 	Packets map[string]*PacketState
 
-	PythonAPITimer    int
-	RabbitMQTimer     int
-	WebsocketAPITimer int
+	PythonAPI    *StageState
+	RabbitMQ     *StageState
+	WebsocketAPI *StageState
+
+	// Devices son los Geova de la escena, en el orden de la configuración.
+	// Selected es el que se inclina con los controles y el que muestran el
+	// frontend y las gráficas.
+	Devices  []*DeviceState
+	Selected int
 
-	DisplayDistancia   float64
-	DisplayRoll        float64
-	DisplayNitidez     float64
-	CurrentTilt        float64
 	SimulacionIniciada bool
+	Streaming          bool // Modo streaming del MPU activo
+}
+
+// Device busca un dispositivo por ID; retorna nil si no existe.
+func (v *VisualState) Device(id string) *DeviceState {
+	for _, d := range v.Devices {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// SelectedDevice retorna el dispositivo seleccionado o nil si no hay
+// dispositivos.
+func (v *VisualState) SelectedDevice() *DeviceState {
+	if v.Selected < 0 || v.Selected >= len(v.Devices) {
+		return nil
+	}
+	return v.Devices[v.Selected]
 }